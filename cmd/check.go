@@ -19,8 +19,12 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/mdhender/farHorizons/internal/fh"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -29,59 +33,77 @@ import (
 var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check the integrity of the galaxy file",
-	Long:  `Runs optional checks.`,
+	Long: `Checks that every invariant of the galaxy holds. Each problem is
+printed on its own line as "check: subject: message", or as a json
+array with --json. The command exits with a non-zero status if any
+check fails.
+
+Use --check to run only some of the checks. The checks are:
+
+` + checkList(),
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := "D:/GoLand/farHorizons/testdata/galaxy.json"
-		// load all the data
-		galaxy, err := fh.GetGalaxy(name)
+		name, err := galaxyFile(cmd)
 		if err != nil {
 			return err
 		}
+		// load all the data; dangling references are reported by the
+		// references check
+		galaxy, err := fh.GetGalaxy(name)
+		var dangling *fh.DanglingError
+		if err != nil && !errors.As(err, &dangling) {
+			return err
+		}
 
-		allChecks, err := cmd.Flags().GetBool("all")
+		checks, err := cmd.Flags().GetStringSlice("check")
 		if err != nil {
 			return err
 		}
-		duplicateStars, err := cmd.Flags().GetBool("duplicate-stars")
+		asJSON, err := cmd.Flags().GetBool("json")
 		if err != nil {
 			return err
 		}
 
-		var errors []error
-
-		if allChecks || duplicateStars {
-			stars := make(map[string]bool)
-			for _, star := range galaxy.AllStars() {
-				coords := fmt.Sprintf("%04d/%04d/%04d", star.X, star.Y, star.Z)
-				if ok := stars[coords]; ok {
-					errors = append(errors, fmt.Errorf("duplicate star %d %d %d", star.X, star.Y, star.Z))
-				}
-				stars[coords] = true
+		problems, err := galaxy.Check(checks...)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			b, err := json.MarshalIndent(problems, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+		} else {
+			for _, problem := range problems {
+				fmt.Println(problem)
 			}
 		}
 
-		totalPlanets := 0
-		for _, star := range galaxy.AllStars() {
-			totalPlanets += len(star.Planets)
+		if len(problems) != 0 {
+			return fmt.Errorf("%d problems found", len(problems))
 		}
-		if galaxy.NumberOfPlanets != totalPlanets {
-			errors = append(errors, fmt.Errorf("galaxy planet count %d: actual count %d", galaxy.NumberOfPlanets, totalPlanets))
-		}
-
-		if errors != nil {
-			for _, err := range errors {
-				fmt.Printf("%+v\n", err)
-			}
-			return fmt.Errorf("errors found")
+		if !asJSON {
+			fmt.Fprintln(os.Stderr, "no problems found")
 		}
-
-		fmt.Printf("no errors found")
 		return nil
 	},
 }
 
+// checkList returns the names and descriptions of the checks for the
+// help text.
+func checkList() string {
+	var sb strings.Builder
+	for _, check := range fh.CheckNames() {
+		fmt.Fprintf(&sb, "  %-16s %s\n", check[0], check[1])
+	}
+	return sb.String()
+}
+
 func init() {
 	rootCmd.AddCommand(checkCmd)
-	checkCmd.Flags().Bool("all", false, "perform all checks")
-	checkCmd.Flags().BoolP("duplicate-stars", "s", false, "check for duplicate stars")
+	checkCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
+	checkCmd.Flags().StringSlice("check", nil, "run only the named checks")
+	checkCmd.Flags().Bool("json", false, "print the problems as json")
 }
@@ -0,0 +1,122 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// checkOrdersCmd implements the check orders command
+var checkOrdersCmd = &cobra.Command{
+	Use:   "orders [orders-file]",
+	Short: "Check an orders file without running it",
+	Long: `Parses an orders file and checks the ships, planets, species,
+items and ship classes in it against the current state of the species.
+Each problem is reported with its line number and, when possible, a
+suggested fix. No game data is changed.
+
+The default orders file is the species' spNN.ord in the orders
+directory of the game.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		speciesName, err := cmd.Flags().GetString("species")
+		if err != nil {
+			return err
+		} else if speciesName == "" {
+			return fmt.Errorf("you must specify a species")
+		}
+
+		galaxy, err := fh.GetGalaxy(galaxyFileName)
+		if err != nil {
+			return err
+		}
+		species := findSpecies(galaxy, speciesName)
+		if species == nil {
+			return fmt.Errorf("there is no species %q", speciesName)
+		}
+		var ordersFileName string
+		if len(args) != 0 {
+			ordersFileName = args[0]
+		} else {
+			ws, err := gameWorkspace()
+			if err != nil {
+				return err
+			}
+			ordersFileName = filepath.Join(ws.OrdersDir(), fmt.Sprintf("sp%s.ord", species.ID))
+		}
+
+		var problems orders.ErrorList
+		o, err := orders.ParseFile(ordersFileName)
+		if list, ok := err.(orders.ErrorList); ok {
+			problems = append(problems, list...)
+		} else if err != nil {
+			return err
+		}
+		problems = append(problems, orders.Check(o, galaxy, species)...)
+		sort.SliceStable(problems, func(i, j int) bool {
+			return problems[i].Line < problems[j].Line
+		})
+
+		if problems == nil {
+			fmt.Printf("%s: no problems found for SP %s\n", ordersFileName, species.Name)
+			return nil
+		}
+		for _, problem := range problems {
+			fmt.Printf("%s:%d: %s\n", ordersFileName, problem.Line, problem.Msg)
+			if problem.Hint != "" {
+				fmt.Printf("\t%s\n", problem.Hint)
+			}
+		}
+		return fmt.Errorf("found %d problems", len(problems))
+	},
+}
+
+// findSpecies accepts a species id (01 or SP01), a species number or a species name.
+func findSpecies(g *fh.GalaxyData, name string) *fh.SpeciesData {
+	id := name
+	if len(id) > 2 && strings.EqualFold(id[:2], "SP") {
+		id = id[2:]
+	}
+	if n, err := strconv.Atoi(id); err == nil {
+		id = fmt.Sprintf("%02d", n)
+	}
+	if species := g.GetSpeciesByID(id); species != nil {
+		return species
+	}
+	return g.GetSpeciesByName(name)
+}
+
+func init() {
+	checkCmd.AddCommand(checkOrdersCmd)
+	checkOrdersCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to check against (default is galaxy.json in the game directory)")
+	checkOrdersCmd.Flags().StringP("species", "s", "", "species id, number or name")
+	_ = checkOrdersCmd.MarkFlagRequired("species")
+}
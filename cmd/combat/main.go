@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Combat is a shortcut for "farHorizons run combat".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "run", "combat"}, os.Args[1:]...)
+	cmd.Execute()
 }
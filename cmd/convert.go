@@ -83,7 +83,10 @@ systems.`,
 			return fmt.Errorf("specify either one or co-ordinates, not both")
 		}
 
-		name := "D:/GoLand/farHorizons/testdata/galaxy.json"
+		name, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
 		g, err := fh.GetGalaxy(name)
 		if err != nil {
 			return err
@@ -93,9 +96,6 @@ systems.`,
 			return fmt.Errorf("minimum-distance must be between 1 and %d", g.Radius*2)
 		}
 
-		// seed random number generator
-		fh.Seed(0xC0FFEE)
-
 		if reset {
 			for _, star := range g.AllStars() {
 				if star.HomeSystem {
@@ -138,7 +138,7 @@ systems.`,
 			}
 
 			// fetch the home system template and update the star with values from the template
-			star.ConvertToHomeSystem(g.Templates.Homes[star.NumPlanets])
+			star.ConvertToHomeSystem(g.Stream(fh.GENERATION_STREAM), g.Templates.Homes[star.NumPlanets])
 			star.HomeSystem = true
 			fmt.Printf("Converted system %d %d %d, home planet %d\n", x, y, z, star.HomePlanetNumber())
 			systemsConverted++
@@ -151,6 +151,7 @@ systems.`,
 
 func init() {
 	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
 	convertCmd.Flags().Bool("all", false, "convert randomly picked systems, up to the species limit")
 	convertCmd.Flags().Bool("forbid-nearby-wormholes", false, "forbid wormholes to be neighbors")
 	convertCmd.Flags().Bool("one", false, "convert one randomly picked system")
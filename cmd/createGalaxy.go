@@ -21,8 +21,10 @@ package cmd
 import (
 	"fmt"
 	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/workspace"
 	"github.com/spf13/cobra"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -34,19 +36,20 @@ var createGalaxyCmd = &cobra.Command{
 configuration file, then creates a new galaxy file.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		started := time.Now()
-		fh.Seed(0xC0FFEE) // seed random number generator
 
-		galaxyFileName, err := cmd.Flags().GetString("galaxy-file")
+		ws, err := gameWorkspace()
 		if err != nil {
 			return err
-		} else if galaxyFileName == "" {
-			return fmt.Errorf("you must specify a valid file name to create")
+		} else if err := ws.Create(); err != nil {
+			return err
 		}
-		setupFileName, err := cmd.Flags().GetString("setup-file")
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		setupFileName, err := pathFlag(cmd, "setup-file", (*workspace.Workspace).SetupFile)
 		if err != nil {
 			return err
-		} else if setupFileName == "" {
-			return fmt.Errorf("you must specify a valid setup file name")
 		}
 
 		setupData, err := fh.GetSetup(setupFileName)
@@ -54,8 +57,20 @@ configuration file, then creates a new galaxy file.`,
 			return err
 		}
 
+		// seed random number generator
+		seed, err := cmd.Flags().GetUint64("seed")
+		if err != nil {
+			return err
+		} else if seed == 0 {
+			seed = setupData.Galaxy.Seed
+		}
+		if seed == 0 {
+			seed = fh.NewSeed()
+		}
+		fmt.Printf("Using seed %d.\n", seed)
+
 		// NewGalaxy step in setup_game.py
-		g, err := fh.GenerateGalaxy(setupData)
+		g, err := fh.GenerateGalaxy(setupData, seed)
 		if err != nil {
 			return err
 		}
@@ -69,7 +84,7 @@ configuration file, then creates a new galaxy file.`,
 			fmt.Printf("Creating home system with %d planets...\n", num_planets)
 			var planets []*fh.PlanetData
 			for planets == nil {
-				planets = fh.GenerateEarthLikePlanet(fmt.Sprintf("homes/%02d", num_planets), num_planets)
+				planets = fh.GenerateEarthLikePlanet(g.Stream(fh.GENERATION_STREAM), fmt.Sprintf("homes/%02d", num_planets), num_planets)
 			}
 			g.Templates.Homes[num_planets] = planets
 		}
@@ -102,7 +117,7 @@ configuration file, then creates a new galaxy file.`,
 				return fmt.Errorf("There is no star at %d %d %d", x, y, z)
 			}
 			// fetch the home system template and update the star with values from the template
-			star.ConvertToHomeSystem(g.Templates.Homes[star.NumPlanets])
+			star.ConvertToHomeSystem(g.Stream(fh.GENERATION_STREAM), g.Templates.Homes[star.NumPlanets])
 			pn := star.HomePlanetNumber()
 			fmt.Printf("Converted system %d %d %d, home planet %d\n", x, y, z, pn)
 
@@ -186,7 +201,7 @@ configuration file, then creates a new galaxy file.`,
 			// Start with the good_gas array and add neutral gases until there are exactly seven of them.
 			// One of the seven gases will be the required gas.
 			for num_neutral < 7 {
-				if n := fh.Roll(13); !goodGas[n] {
+				if n := g.Stream(fh.GENERATION_STREAM).Roll(13); !goodGas[n] {
 					goodGas[n] = true
 					num_neutral++
 				}
@@ -206,16 +221,17 @@ configuration file, then creates a new galaxy file.`,
 			// Initial mining and production capacity will be 25 times sum of MI and MA plus a small random amount.
 			// Mining and manufacturing base will be reverse-calculated from the capacity.
 			levels := spec.TechLevel[fh.MI] + spec.TechLevel[fh.MA]
-			n := (25 * levels) + fh.Roll(levels) + fh.Roll(levels) + fh.Roll(levels)
+			n := (25 * levels) + g.Stream(fh.GENERATION_STREAM).Roll(levels) + g.Stream(fh.GENERATION_STREAM).Roll(levels) + g.Stream(fh.GENERATION_STREAM).Roll(levels)
 			home_nampla.MIBase = (n * spec.HomePlanet.MiningDifficulty) / (10 * spec.TechLevel[fh.MI])
 			home_nampla.MABase = (10 * n) / spec.TechLevel[fh.MA]
 
-			// initialize contact/ally/enemy masks
-			spec.Contact = make([]bool, g.DNumSpecies+1, g.DNumSpecies+1)
-			spec.Ally = make([]bool, g.DNumSpecies+1, g.DNumSpecies+1)
-			spec.Enemy = make([]bool, g.DNumSpecies+1, g.DNumSpecies+1)
+			// initialize contact/ally/enemy sets
+			spec.Contact = make(fh.SpeciesSet)
+			spec.Ally = make(fh.SpeciesSet)
+			spec.Enemy = make(fh.SpeciesSet)
 
 			spec.NumNamplas = 1 // just the home planet for now ("nampla" means "named planet")
+			spec.Namplas = append(spec.Namplas, home_nampla)
 			home_nampla.Status = fh.HOME_PLANET | fh.POPULATED
 			home_nampla.PopUnits = fh.HP_AVAILABLE_POP
 			home_nampla.Shipyards = 1
@@ -265,7 +281,7 @@ configuration file, then creates a new galaxy file.`,
 			g.Species[spec.ID] = &spec
 
 			/* Create log file for first turn. Write home star system data to it. */
-			logFile := fmt.Sprintf("D:/GoLand/farHorizons/testdata/sp%02d.log", spec.Number)
+			logFile := filepath.Join(ws.ReportsDir(), fmt.Sprintf("sp%02d.log", spec.Number))
 			w, err := os.Create(logFile)
 			if err != nil {
 				return err
@@ -290,8 +306,7 @@ configuration file, then creates a new galaxy file.`,
 
 func init() {
 	createCmd.AddCommand(createGalaxyCmd)
-	createGalaxyCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to create")
-	_ = createGalaxyCmd.MarkFlagRequired("galaxy-file")
-	createGalaxyCmd.Flags().StringP("setup-file", "i", "", "name of configuration file to load")
-	_ = createGalaxyCmd.MarkFlagRequired("setup-file")
+	createGalaxyCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to create (default is galaxy.json in the game directory)")
+	createGalaxyCmd.Flags().StringP("setup-file", "i", "", "name of configuration file to load (default is setup.json in the game directory)")
+	createGalaxyCmd.Flags().Uint64("seed", 0, "seed for the random number generator, overrides the setup file")
 }
@@ -32,20 +32,20 @@ var createHomesCmd = &cobra.Command{
 that have a home planet. It randomly populates a template for systems
 containing from 3 to 9 planets.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := "D:/GoLand/farHorizons/testdata/galaxy.json"
+		name, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
 		g, err := fh.GetGalaxy(name)
 		if err != nil {
 			return err
 		}
 
-		// seed random number generator
-		fh.Seed(0xC0FFEE)
-
 		for num_planets := 3; num_planets < 10; num_planets++ {
 			fmt.Printf("Creating home system with %d planets...\n", num_planets)
 			var planets []*fh.PlanetData
 			for planets == nil {
-				planets = fh.GenerateEarthLikePlanet("sand", num_planets)
+				planets = fh.GenerateEarthLikePlanet(g.Stream(fh.GENERATION_STREAM), "sand", num_planets)
 			}
 			g.Templates.Homes[num_planets] = planets
 		}
@@ -56,4 +56,5 @@ containing from 3 to 9 planets.`,
 
 func init() {
 	createCmd.AddCommand(createHomesCmd)
+	createHomesCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
 }
@@ -0,0 +1,106 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// createMessageCmd implements the create message command
+var createMessageCmd = &cobra.Command{
+	Use:   "message",
+	Short: "Add a message to the catalogue and attach it to a star or planet",
+	Long: `Reads the text of a message from a file, adds it to the galaxy's
+catalogue of messages and attaches it to a star system or, given a
+planet number, to a planet. The message is added to the report of each
+species that visits or scans the location, once per species.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		textFileName, err := cmd.Flags().GetString("text-file")
+		if err != nil {
+			return err
+		} else if !filepath.IsAbs(textFileName) {
+			ws, err := gameWorkspace()
+			if err != nil {
+				return err
+			}
+			textFileName = filepath.Join(ws.MessagesDir(), textFileName)
+		}
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			return err
+		}
+		var xyzp [4]int
+		for i, name := range []string{"x", "y", "z", "planet"} {
+			if xyzp[i], err = cmd.Flags().GetInt(name); err != nil {
+				return err
+			}
+		}
+
+		galaxy, err := fh.GetGalaxy(galaxyFileName)
+		if err != nil {
+			return err
+		}
+		star := galaxy.GetStarAt(xyzp[0], xyzp[1], xyzp[2])
+		if star == nil {
+			return fmt.Errorf("there is no star at %d %d %d", xyzp[0], xyzp[1], xyzp[2])
+		}
+		var planet *fh.PlanetData
+		if pn := xyzp[3]; pn != 0 {
+			if pn < 1 || pn > len(star.Planets) {
+				return fmt.Errorf("there is no planet %d at %d %d %d", pn, xyzp[0], xyzp[1], xyzp[2])
+			}
+			planet = star.Planets[pn-1]
+		}
+
+		b, err := ioutil.ReadFile(textFileName)
+		if err != nil {
+			return err
+		}
+		text := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+		id := galaxy.AddEventMessage(&fh.EventMessage{Title: title, Text: text})
+		if planet != nil {
+			planet.Message = id
+		} else {
+			star.Message = id
+		}
+		fmt.Printf("Added message %d at %d %d %d #%d\n", id, xyzp[0], xyzp[1], xyzp[2], xyzp[3])
+		return galaxy.Write(galaxyFileName)
+	},
+}
+
+func init() {
+	createCmd.AddCommand(createMessageCmd)
+	createMessageCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to update (default is galaxy.json in the game directory)")
+	createMessageCmd.Flags().StringP("text-file", "f", "", "name of file in the messages directory containing the text of the message")
+	_ = createMessageCmd.MarkFlagRequired("text-file")
+	createMessageCmd.Flags().String("title", "", "optional title printed above the message")
+	createMessageCmd.Flags().IntP("x", "x", 0, "x coordinate of the star")
+	createMessageCmd.Flags().IntP("y", "y", 0, "y coordinate of the star")
+	createMessageCmd.Flags().IntP("z", "z", 0, "z coordinate of the star")
+	createMessageCmd.Flags().IntP("planet", "p", 0, "planet number, or 0 to attach the message to the star")
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/workspace"
 	"github.com/spf13/cobra"
 	"io/ioutil"
 )
@@ -33,12 +34,15 @@ var createSetupCmd = &cobra.Command{
 	Long: `This command creates a new setup file ready to be
 filled out with player information.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filename, err := cmd.Flags().GetString("file-name")
+		ws, err := gameWorkspace()
 		if err != nil {
 			return err
+		} else if err := ws.Create(); err != nil {
+			return err
 		}
-		if filename == "" {
-			return fmt.Errorf("you must specify a valid file name to create")
+		filename, err := pathFlag(cmd, "file-name", (*workspace.Workspace).SetupFile)
+		if err != nil {
+			return err
 		}
 		forbidNearbyWormholes, err := cmd.Flags().GetBool("forbid-nearby-wormholes")
 		if err != nil {
@@ -67,15 +71,24 @@ filled out with player information.`,
 			return fmt.Errorf("number of players must be between %d and %d", fh.MIN_SPECIES, fh.MAX_SPECIES)
 		}
 
+		seed, err := cmd.Flags().GetUint64("seed")
+		if err != nil {
+			return err
+		} else if seed == 0 {
+			seed = fh.NewSeed()
+		}
+		rng := fh.NewRNG(seed)
+
 		var s fh.SetupData
 		s.Galaxy.Name = galaxyName
+		s.Galaxy.Seed = seed
 		s.Galaxy.ForbidNearbyWormholes = forbidNearbyWormholes
 		s.Galaxy.LowDensity = lowDensity
 		s.Galaxy.MinimumDistance = minDistance
 		for i := 1; i <= numberOfPlayers; i++ {
 			ml, gv, ls, bi := 1, 1, 1, 1
 			for k := 5; k <= 15; k++ {
-				switch fh.Roll(4) {
+				switch rng.Roll(4) {
 				case 1:
 					ml++
 				case 2:
@@ -113,11 +126,11 @@ func init() {
 	createCmd.AddCommand(createSetupCmd)
 	createSetupCmd.Flags().StringP("galaxy-name", "g", "", "name of galaxy to be setup")
 	_ = createSetupCmd.MarkFlagRequired("galaxy-name")
-	createSetupCmd.Flags().StringP("file-name", "f", "", "name of file to create")
-	_ = createSetupCmd.MarkFlagRequired("file-name")
+	createSetupCmd.Flags().StringP("file-name", "f", "", "name of file to create (default is setup.json in the game directory)")
 	createSetupCmd.Flags().IntP("number-of-players", "n", 0, "number of player entries to create")
 	_ = createSetupCmd.MarkFlagRequired("number-of-players")
 	createSetupCmd.Flags().Bool("forbid-nearby-wormholes", false, "forbid wormholes to be neighbors")
 	createSetupCmd.Flags().Bool("low-density", false, "increase the radius by 50%")
 	createSetupCmd.Flags().IntP("minimum-distance", "d", 10, "minimum distance between home systems")
+	createSetupCmd.Flags().Uint64("seed", 0, "seed for the random number generator, zero to use the time")
 }
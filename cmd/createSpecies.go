@@ -30,20 +30,21 @@ var createSpeciesCmd = &cobra.Command{
 	Long: `This command creates a new species record using information
 from ???something???`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := "D:/GoLand/farHorizons/testdata/galaxy.json"
+		name, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
 		g, err := fh.GetGalaxy(name)
 		if err != nil {
 			return err
 		}
 
-		// seed random number generator
-		fh.Seed(0xC0FFEE)
-
 		return g.Write(name)
 	},
 }
 
 func init() {
 	createCmd.AddCommand(createSpeciesCmd)
-	createSpeciesCmd.Flags().StringP("species-file", "f", "D:/GoLand/farHorizons/testdata/sp1.json", "file containing species data as a JSON object")
+	createSpeciesCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
+	createSpeciesCmd.Flags().StringP("species-file", "f", "", "file containing species data as a JSON object (default is sp1.json in the game directory)")
 }
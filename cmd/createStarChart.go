@@ -21,6 +21,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
 	"github.com/spf13/cobra"
 	"io/ioutil"
 )
@@ -56,7 +57,11 @@ var createStarChartCmd = &cobra.Command{
 			return err
 		}
 
-		name := "D:/GoLand/farHorizons/testdata/starlist.json"
+		ws, err := gameWorkspace()
+		if err != nil {
+			return err
+		}
+		name := ws.Path("starlist.json")
 		b, err := ioutil.ReadFile(name)
 		if err != nil {
 			return err
@@ -115,21 +120,8 @@ var createStarChartCmd = &cobra.Command{
 					if from == to {
 						continue
 					}
-					deltaX, deltaY, deltaZ := from.X-to.X, from.Y-to.Y, from.Z-to.Z
-					mishap_age := shipAge
-					mishap_gv := graviticsLevel
-					mishap_chance := (100 * (((deltaX) * (deltaX)) + ((deltaY) * (deltaY)) + ((deltaZ) * (deltaZ)))) / mishap_gv
-					if mishap_chance > 10000 {
-						mishap_chance = 10000
-					} else if mishap_age > 0 {
-						/* Add aging effect. */
-						success_chance := 10000 - mishap_chance
-						success_chance -= (2 * mishap_age * success_chance) / 100
-						if success_chance < 0 {
-							success_chance = 0
-						}
-						mishap_chance = 10000 - success_chance
-					}
+					distanceSquared := fh.DistanceSquared(from.X, from.Y, from.Z, to.X, to.Y, to.Z)
+					mishap_chance := fh.MishapChance(distanceSquared, graviticsLevel, shipAge)
 					if mishap_chance > (mishapLimit * 100) {
 						continue
 					}
@@ -161,7 +153,7 @@ var createStarChartCmd = &cobra.Command{
 			ds3.Nodes = append(ds3.Nodes, n)
 		}
 
-		name = "D:/GoLand/farHorizons/testdata/starChart.json"
+		name = ws.Path("starChart.json")
 		if b, err := json.MarshalIndent(&ds3, "  ", "  "); err != nil {
 			return err
 		} else if err := ioutil.WriteFile(name, b, 0644); err != nil {
@@ -0,0 +1,76 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/workspace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// exportCmd implements the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a game to the C server's data files",
+	Long: `Writes the galaxy as the binary galaxy.dat, stars.dat, planets.dat
+and spNN.dat files read by the original C Far Horizons server, so that
+a turn can be run with the C programs if this server can't run it.
+The files can be read back with the import command.
+
+The size of a long must match the machine that will read the files:
+4 for the 32-bit builds, 8 for most 64-bit builds. The ledger, the
+message archive and the random number streams aren't written.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDir, err := pathFlag(cmd, "data-dir", (*workspace.Workspace).LegacyDir)
+		if err != nil {
+			return err
+		}
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		longSize, err := cmd.Flags().GetInt("long-size")
+		if err != nil {
+			return err
+		}
+
+		g, err := fh.GetGalaxy(galaxyFileName)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			return err
+		}
+		if err := fh.ExportLegacy(g, dataDir, longSize); err != nil {
+			return err
+		}
+		fmt.Printf("Exported turn %d: %d stars, %d species.\n", g.TurnNumber, len(g.Stars), len(g.Species))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("data-dir", "d", "", "directory for the C data files (default is legacy in the game directory)")
+	exportCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
+	exportCmd.Flags().Int("long-size", 4, "size in bytes of a C long in the data files")
+}
@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Finish is a shortcut for "farHorizons run finish".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "run", "finish"}, os.Args[1:]...)
+	cmd.Execute()
 }
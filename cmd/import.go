@@ -0,0 +1,93 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// importCmd implements the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a game from the C server's data files",
+	Long: `Reads the binary galaxy.dat, stars.dat, planets.dat and spNN.dat
+files written by the original C Far Horizons server and saves them as
+a galaxy file. Use this to move a game that is in progress onto this
+server.
+
+The files are raw C structs, so the size of a long must match the
+machine that wrote them: 4 for the 32-bit builds, 8 for most 64-bit
+builds. Players aren't stored in the C files and must be added to the
+galaxy file by hand. The C files don't record a seed for the random
+number streams, so one is taken from --seed or, if that isn't given,
+from the clock. The game directory is created if it doesn't exist.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataDir, err := pathFlag(cmd, "data-dir", (*workspace.Workspace).LegacyDir)
+		if err != nil {
+			return err
+		}
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		longSize, err := cmd.Flags().GetInt("long-size")
+		if err != nil {
+			return err
+		}
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+		seed, err := cmd.Flags().GetUint64("seed")
+		if err != nil {
+			return err
+		} else if seed == 0 {
+			seed = fh.NewSeed()
+		}
+		fmt.Printf("Using seed %d.\n", seed)
+
+		ws, err := gameWorkspace()
+		if err != nil {
+			return err
+		} else if err := ws.Create(); err != nil {
+			return err
+		}
+
+		g, err := fh.ImportLegacy(dataDir, longSize, seed)
+		if err != nil {
+			return err
+		}
+		g.ID, g.Name = name, name
+		fmt.Printf("Imported turn %d: %d stars, %d planets, %d species.\n", g.TurnNumber, g.NumberOfStars, g.NumberOfPlanets, len(g.Species))
+		return g.Write(galaxyFileName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringP("data-dir", "d", "", "directory containing the C data files (default is legacy in the game directory)")
+	importCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to create (default is galaxy.json in the game directory)")
+	importCmd.Flags().Int("long-size", 4, "size in bytes of a C long in the data files")
+	importCmd.Flags().String("name", "imported", "name of the galaxy")
+	importCmd.Flags().Uint64("seed", 0, "seed for the random number generator (default is a seed based on the clock)")
+}
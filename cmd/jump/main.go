@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Jump is a shortcut for "farHorizons run jump".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "run", "jump"}, os.Args[1:]...)
+	cmd.Execute()
 }
@@ -19,7 +19,6 @@
 package cmd
 
 import (
-	"fmt"
 	"github.com/mdhender/farHorizons/internal/fh"
 	"github.com/spf13/cobra"
 )
@@ -30,11 +29,9 @@ var listGalaxyCmd = &cobra.Command{
 	Short: "list galaxy properties",
 	Long:  `List details about the galaxy, writing the results to stdout.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		galaxyFileName, err := cmd.Flags().GetString("galaxy-file")
+		galaxyFileName, err := galaxyFile(cmd)
 		if err != nil {
 			return err
-		} else if galaxyFileName == "" {
-			return fmt.Errorf("you must specify a galaxy file to list")
 		}
 		listPlanets, listWormholes := true, false
 		noListPlanets, err := cmd.Flags().GetBool("no-list-planets")
@@ -69,8 +66,7 @@ var listGalaxyCmd = &cobra.Command{
 
 func init() {
 	listCmd.AddCommand(listGalaxyCmd)
-	listGalaxyCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to list")
-	_ = listCmd.MarkFlagRequired("galaxy-file")
+	listGalaxyCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to list (default is galaxy.json in the game directory)")
 	listGalaxyCmd.Flags().BoolP("no-list-planets", "p", false, "do not list planets")
 	listGalaxyCmd.Flags().BoolP("only-wormholes", "w", false, "list only wormholes")
 }
@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Loc is a shortcut for "farHorizons run locations".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "run", "locations"}, os.Args[1:]...)
+	cmd.Execute()
 }
@@ -0,0 +1,68 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd implements the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [galaxy-file...]",
+	Short: "Upgrade galaxy files to the current schema version",
+	Long: `Rewrites each galaxy file in place using the current schema version.
+Each file is upgraded one version at a time. The original file is kept
+next to it as NAME.vN.bak, where N is the version it had. Files that
+are already current are not changed. The default is the galaxy file
+in the game directory.
+
+Older files can still be loaded without migrating them, because they
+are upgraded in memory on every load.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			ws, err := gameWorkspace()
+			if err != nil {
+				return err
+			}
+			args = append(args, ws.GalaxyFile())
+		}
+		for _, name := range args {
+			version, err := fh.MigrateGalaxy(name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if version == fh.SCHEMA_VERSION {
+				fmt.Printf("%s: already at schema version %d\n", name, version)
+				continue
+			}
+			for v := version; v < fh.SCHEMA_VERSION; v++ {
+				fmt.Printf("%s: version %d to %d: %s\n", name, v, v+1, fh.MigrationDescription(v))
+			}
+			fmt.Printf("%s: upgraded, original saved as %s.v%d.bak\n", name, name, version)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+}
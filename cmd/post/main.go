@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Post is a shortcut for "farHorizons run post-arrival".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "run", "post-arrival"}, os.Args[1:]...)
+	cmd.Execute()
 }
@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Pre is a shortcut for "farHorizons run pre-departure".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "run", "pre-departure"}, os.Args[1:]...)
+	cmd.Execute()
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -53,6 +54,10 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.farHorizons.yaml)")
+	rootCmd.PersistentFlags().String("game-dir", "", "game workspace directory (default is the current directory)")
+	rootCmd.PersistentFlags().String("game", "", "name of a game in the games section of the config file")
+	cobra.CheckErr(viper.BindPFlag("game-dir", rootCmd.PersistentFlags().Lookup("game-dir")))
+	cobra.CheckErr(viper.BindPFlag("game", rootCmd.PersistentFlags().Lookup("game")))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -74,7 +79,10 @@ func initConfig() {
 		viper.SetConfigName(".farHorizons")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// read in environment variables that match, e.g. FH_GAME_DIR
+	viper.SetEnvPrefix("FH")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
@@ -0,0 +1,74 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/turn"
+	"github.com/mdhender/farHorizons/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// runCmd implements the run command
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a phase of the turn",
+	Long: `Run a single phase of the turn against the galaxy file.
+The phases must be run in order. See the README for the sequence.`,
+}
+
+// runPhase loads the galaxy and orders, runs the phase, then saves the
+// species logs and the updated galaxy.
+func runPhase(cmd *cobra.Command, phase func(t *turn.Turn) error) error {
+	galaxyFileName, err := galaxyFile(cmd)
+	if err != nil {
+		return err
+	}
+	ordersDir, err := pathFlag(cmd, "orders-dir", (*workspace.Workspace).OrdersDir)
+	if err != nil {
+		return err
+	}
+	logDir, err := pathFlag(cmd, "log-dir", (*workspace.Workspace).ReportsDir)
+	if err != nil {
+		return err
+	}
+
+	galaxy, err := fh.GetGalaxy(galaxyFileName)
+	if err != nil {
+		return err
+	}
+	t, err := turn.New(galaxy, ordersDir, logDir)
+	if err != nil {
+		return err
+	}
+	if err := phase(t); err != nil {
+		return err
+	}
+	if err := t.WriteLogs(); err != nil {
+		return err
+	}
+	return t.Galaxy.Write(galaxyFileName)
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.PersistentFlags().StringP("galaxy-file", "g", "", "name of galaxy file to update (default is galaxy.json in the game directory)")
+	runCmd.PersistentFlags().String("orders-dir", "", "directory containing the spNN.ord files (default is orders in the game directory)")
+	runCmd.PersistentFlags().String("log-dir", "", "directory for the spNN.log files (default is reports in the game directory)")
+}
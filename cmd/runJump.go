@@ -0,0 +1,39 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mdhender/farHorizons/internal/turn"
+	"github.com/spf13/cobra"
+)
+
+// runJumpCmd implements the run jump command
+var runJumpCmd = &cobra.Command{
+	Use:   "jump",
+	Short: "Run the jump phase",
+	Long: `Runs the JUMPS section of every species' orders. Ships are moved
+by JUMP, MOVE and WORMHOLE orders and jump mishaps are resolved.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPhase(cmd, (*turn.Turn).Jump)
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runJumpCmd)
+}
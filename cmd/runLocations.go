@@ -0,0 +1,40 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mdhender/farHorizons/internal/turn"
+	"github.com/spf13/cobra"
+)
+
+// runLocationsCmd implements the run locations command
+var runLocationsCmd = &cobra.Command{
+	Use:     "locations",
+	Aliases: []string{"loc"},
+	Short:   "Run the locations phase",
+	Long: `Finds the species that share star systems and records the contacts
+between them. It is the first phase of the turn.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPhase(cmd, (*turn.Turn).Locations)
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runLocationsCmd)
+}
@@ -0,0 +1,50 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mdhender/farHorizons/internal/turn"
+	"github.com/spf13/cobra"
+)
+
+// runReportCmd implements the run report command
+var runReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write the turn reports",
+	Long: `Writes a report for every species to spNN.rpt.tTT, where TT is the
+turn number. The report includes the species log for the turn, which is
+removed once the report has been written.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reportDir, err := cmd.Flags().GetString("report-dir")
+		if err != nil {
+			return err
+		}
+		return runPhase(cmd, func(t *turn.Turn) error {
+			if reportDir != "" {
+				t.ReportDir = reportDir
+			}
+			return t.Report()
+		})
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runReportCmd)
+	runReportCmd.Flags().String("report-dir", "", "directory for the reports (default is the log directory)")
+}
@@ -0,0 +1,81 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/spf13/cobra"
+	"strconv"
+	"strings"
+)
+
+// showLocationsCmd implements the show locations command
+var showLocationsCmd = &cobra.Command{
+	Use:   "locations [x y z]",
+	Short: "Show the species present in each star system",
+	Long: `Lists every location where a species has ships or named planets.
+Given coordinates, lists only the species present at that location.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 3 {
+			return fmt.Errorf("expected x y z or no arguments")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		galaxy, err := fh.GetGalaxy(galaxyFileName)
+		if err != nil {
+			return err
+		}
+		index := fh.NewLocationIndex(galaxy)
+
+		if len(args) == 3 {
+			var xyz [3]int
+			for i, arg := range args {
+				if xyz[i], err = strconv.Atoi(arg); err != nil {
+					return fmt.Errorf("invalid coordinate %q", arg)
+				}
+			}
+			var names []string
+			for _, sp := range index.SpeciesAt(xyz[0], xyz[1], xyz[2]) {
+				names = append(names, fmt.Sprintf("SP %s (%d)", sp.Name, sp.Number))
+			}
+			if len(names) == 0 {
+				fmt.Printf("%d %d %d: no species present\n", xyz[0], xyz[1], xyz[2])
+				return nil
+			}
+			fmt.Printf("%d %d %d: %s\n", xyz[0], xyz[1], xyz[2], strings.Join(names, ", "))
+			return nil
+		}
+
+		for _, loc := range index.Locations {
+			fmt.Printf("%2d %-24s %3d %3d %3d\n", loc.Species.Number, loc.Species.Name, loc.X, loc.Y, loc.Z)
+		}
+		return nil
+	},
+}
+
+func init() {
+	showCmd.AddCommand(showLocationsCmd)
+	showLocationsCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to read (default is galaxy.json in the game directory)")
+}
@@ -0,0 +1,76 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/spf13/cobra"
+)
+
+// showMessagesCmd implements the show messages command
+var showMessagesCmd = &cobra.Command{
+	Use:   "messages",
+	Short: "Show the archive of messages sent between species",
+	Long: `Lists every message sent between species during the game, oldest first.
+Use --species to list only the messages sent to or from one species.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		speciesID, err := cmd.Flags().GetString("species")
+		if err != nil {
+			return err
+		}
+		galaxy, err := fh.GetGalaxy(galaxyFileName)
+		if err != nil {
+			return err
+		}
+
+		messages := galaxy.Messages
+		if speciesID != "" {
+			if galaxy.GetSpeciesByID(speciesID) == nil {
+				return fmt.Errorf("there is no species %q", speciesID)
+			}
+			messages = galaxy.MessagesFor(speciesID)
+		}
+		for _, m := range messages {
+			fmt.Printf("Message %d, turn %d, from SP %s to SP %s:\n", m.ID, m.Turn, speciesName(galaxy, m.From), speciesName(galaxy, m.To))
+			for _, line := range m.Text {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+		return nil
+	},
+}
+
+// speciesName returns the name of a species, or its id if there is no such species.
+func speciesName(galaxy *fh.GalaxyData, id string) string {
+	if sp := galaxy.GetSpeciesByID(id); sp != nil {
+		return sp.Name
+	}
+	return id
+}
+
+func init() {
+	showCmd.AddCommand(showMessagesCmd)
+	showMessagesCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to read (default is galaxy.json in the game directory)")
+	showMessagesCmd.Flags().String("species", "", "species id, e.g. 01, to list messages for")
+}
@@ -30,7 +30,10 @@ var showTurnCmd = &cobra.Command{
 	Short: "Show turn number",
 	Long:  `The command line interface to show turn information.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := "D:/GoLand/farHorizons/testdata/galaxy.json"
+		name, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
 
 		// Get galaxy data.
 		galaxy, err := fh.GetGalaxy(name)
@@ -47,6 +50,7 @@ var showTurnCmd = &cobra.Command{
 
 func init() {
 	showCmd.AddCommand(showTurnCmd)
+	showTurnCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
 
 	// Here you will define your flags and configuration settings.
 
@@ -19,9 +19,12 @@
 package main
 
 import (
-	"log"
+	"github.com/mdhender/farHorizons/cmd"
+	"os"
 )
 
+// Turn is a shortcut for "farHorizons turn run".
 func main() {
-	log.Fatal("not implemented")
+	os.Args = append([]string{os.Args[0], "turn", "run"}, os.Args[1:]...)
+	cmd.Execute()
 }
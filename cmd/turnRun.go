@@ -0,0 +1,135 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/turn"
+	"github.com/mdhender/farHorizons/internal/workspace"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+// turnRunCmd implements the turn run command
+var turnRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a complete turn",
+	Long: `Runs every phase of the turn in order: locations, combat,
+pre-departure, jumps, production, post-arrival, strikes, finish, report
+and stats.
+
+The galaxy is saved to the checkpoint directory after each phase. If a
+phase fails, fix the problem and run the command again with --resume to
+restart from the failed phase. The galaxy file is only updated once the
+whole turn has been run.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		ordersDir, err := pathFlag(cmd, "orders-dir", (*workspace.Workspace).OrdersDir)
+		if err != nil {
+			return err
+		}
+		logDir, err := pathFlag(cmd, "log-dir", (*workspace.Workspace).ReportsDir)
+		if err != nil {
+			return err
+		}
+		reportDir, err := cmd.Flags().GetString("report-dir")
+		if err != nil {
+			return err
+		}
+		// the galaxy file isn't updated until the turn is complete, so
+		// its turn number is the number of the turn being run
+		checkpointDir, err := cmd.Flags().GetString("checkpoint-dir")
+		if err != nil {
+			return err
+		} else if checkpointDir == "" {
+			ws, err := gameWorkspace()
+			if err != nil {
+				return err
+			}
+			galaxy, err := fh.GetGalaxy(galaxyFileName)
+			if err != nil {
+				return err
+			}
+			checkpointDir = ws.TurnDir(galaxy.TurnNumber)
+		}
+		resume, err := cmd.Flags().GetBool("resume")
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(checkpointDir, 0755); err != nil {
+			return err
+		}
+		cp, err := turn.ReadCheckpoint(checkpointDir)
+		if err != nil {
+			return err
+		} else if cp != nil && !resume {
+			return fmt.Errorf("turn %d was not finished, use --resume to continue it", cp.TurnNumber)
+		} else if cp == nil && resume {
+			return fmt.Errorf("there is no turn to resume in %s", checkpointDir)
+		}
+
+		var galaxy *fh.GalaxyData
+		if cp != nil && cp.GalaxyFile != "" {
+			galaxy, err = fh.GetGalaxy(cp.GalaxyFile)
+		} else {
+			galaxy, err = fh.GetGalaxy(galaxyFileName)
+		}
+		if err != nil {
+			return err
+		}
+		if cp == nil {
+			cp = turn.NewCheckpoint(galaxy)
+		} else {
+			fmt.Printf("Resuming turn %d after %s.\n", cp.TurnNumber, strings.Join(cp.Completed, ", "))
+		}
+
+		t, err := turn.New(galaxy, ordersDir, logDir)
+		if err != nil {
+			return err
+		}
+		if reportDir != "" {
+			t.ReportDir = reportDir
+		}
+		if err := t.RunPhases(cp, checkpointDir); err != nil {
+			return fmt.Errorf("turn %d: %w\nfix the problem and run again with --resume", cp.TurnNumber, err)
+		}
+
+		if err := t.Galaxy.Write(galaxyFileName); err != nil {
+			return err
+		}
+		return turn.RemoveCheckpoint(checkpointDir)
+	},
+}
+
+func init() {
+	turnCmd.AddCommand(turnRunCmd)
+	turnRunCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file to update (default is galaxy.json in the game directory)")
+	turnRunCmd.Flags().String("orders-dir", "", "directory containing the spNN.ord files (default is orders in the game directory)")
+	turnRunCmd.Flags().String("log-dir", "", "directory for the spNN.log files (default is reports in the game directory)")
+	turnRunCmd.Flags().String("report-dir", "", "directory for the reports (default is the log directory)")
+	turnRunCmd.Flags().String("checkpoint-dir", "", "directory for the checkpoints (default is turns/NNN in the game directory)")
+	turnRunCmd.Flags().Bool("resume", false, "resume a turn that failed part way through")
+}
@@ -0,0 +1,93 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/turn"
+	"github.com/mdhender/farHorizons/internal/workspace"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"os"
+)
+
+// turnVerifyCmd implements the turn verify command
+var turnVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Run the last turn again and check that the result is the same",
+	Long: `Loads the galaxy saved in the checkpoint directory before the first
+phase of a turn, runs the turn again with the orders saved in the same
+directory and the saved random number state, and compares the hash of the result with the
+galaxy saved after the turn's last phase. On a mismatch the first
+species, planet or ship that differs is reported.
+
+By default the turn before the galaxy file's current turn is verified.
+The logs and reports from the new run are written to a temporary
+directory, so the real reports aren't touched.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		galaxyFileName, err := galaxyFile(cmd)
+		if err != nil {
+			return err
+		}
+		turnNumber, err := cmd.Flags().GetInt("turn")
+		if err != nil {
+			return err
+		}
+		if turnNumber == 0 {
+			galaxy, err := fh.GetGalaxy(galaxyFileName)
+			if err != nil {
+				return err
+			}
+			turnNumber = galaxy.TurnNumber - 1
+		}
+		checkpointDir, err := pathFlag(cmd, "checkpoint-dir", func(ws *workspace.Workspace) string {
+			return ws.TurnDir(turnNumber)
+		})
+		if err != nil {
+			return err
+		}
+
+		workDir, err := ioutil.TempDir("", "fh-verify-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(workDir)
+
+		v, err := turn.Verify(checkpointDir, workDir, turnNumber)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Turn %d: expected %s\n", v.TurnNumber, v.Expected)
+		fmt.Printf("Turn %d: replayed %s\n", v.TurnNumber, v.Actual)
+		if !v.OK() {
+			return fmt.Errorf("turn %d does not replay: first difference is %s", v.TurnNumber, v.Difference)
+		}
+		fmt.Printf("Turn %d replays exactly.\n", v.TurnNumber)
+		return nil
+	},
+}
+
+func init() {
+	turnCmd.AddCommand(turnVerifyCmd)
+	turnVerifyCmd.Flags().StringP("galaxy-file", "g", "", "name of galaxy file (default is galaxy.json in the game directory)")
+	turnVerifyCmd.Flags().String("checkpoint-dir", "", "directory for the checkpoints (default is turns/NNN in the game directory)")
+	turnVerifyCmd.Flags().Int("turn", 0, "turn to verify (default is the last turn run)")
+}
@@ -0,0 +1,67 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/workspace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// gameWorkspace returns the workspace of the game selected by --game-dir
+// or --game. Either can also be set in the config file or as FH_GAME_DIR
+// and FH_GAME. A game name is looked up in the "games" map of the config
+// file. The default is the current directory.
+func gameWorkspace() (*workspace.Workspace, error) {
+	dir := viper.GetString("game-dir")
+	if dir == "" {
+		if name := viper.GetString("game"); name != "" {
+			dir = viper.GetStringMapString("games")[strings.ToLower(name)]
+			if dir == "" {
+				return nil, fmt.Errorf("game %q is not listed in the games section of the config file", name)
+			}
+		}
+	}
+	if dir == "" {
+		dir = "."
+	}
+	return workspace.New(dir), nil
+}
+
+// galaxyFile returns the galaxy file named by the galaxy-file flag, or
+// the galaxy file in the workspace if the flag isn't given.
+func galaxyFile(cmd *cobra.Command) (string, error) {
+	return pathFlag(cmd, "galaxy-file", (*workspace.Workspace).GalaxyFile)
+}
+
+// pathFlag returns the file or directory named by a flag, or the one in
+// the workspace if the flag isn't given.
+func pathFlag(cmd *cobra.Command, flag string, fromWorkspace func(*workspace.Workspace) string) (string, error) {
+	name, err := cmd.Flags().GetString(flag)
+	if err != nil || name != "" {
+		return name, err
+	}
+	ws, err := gameWorkspace()
+	if err != nil {
+		return "", err
+	}
+	return fromWorkspace(ws), nil
+}
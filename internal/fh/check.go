@@ -0,0 +1,250 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MAX_TECH_LEVEL is the highest tech level that fits in the C server's
+// data files, which store tech levels in a short.
+const MAX_TECH_LEVEL = 1<<15 - 1
+
+// Problem is an invariant that doesn't hold in a galaxy.
+type Problem struct {
+	Check   string `json:"check"`   // name of the check that failed
+	Subject string `json:"subject"` // the star, planet or species that failed it
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Check, p.Subject, p.Message)
+}
+
+// galaxyCheck is one integrity check. Each check reports every problem
+// it finds rather than stopping at the first.
+type galaxyCheck struct {
+	name, description string
+	run               func(g *GalaxyData, report func(subject, format string, a ...interface{}))
+}
+
+var galaxyChecks = []galaxyCheck{
+	{"references", "every star, planet, species and named planet referred to exists", checkReferences},
+	{"counts", "the galaxy's star, planet and wormhole counts match its stars", checkCounts},
+	{"star-radius", "every star is inside the galactic radius", checkStarRadius},
+	{"wormholes", "every wormhole leads to a star whose wormhole leads back", checkWormholes},
+	{"num-planets", "every star's planet count matches its planets", checkNumPlanets},
+	{"home-systems", "every home system has exactly one ideal home planet", checkHomeSystems},
+	{"gases", "the gases in every atmosphere add up to 100 percent", checkGases},
+	{"tech-levels", "every species' tech levels are in range", checkTechLevels},
+	{"names", "species names are unique, and each species' planet and ship names are unique", checkNames},
+}
+
+// CheckNames returns the names and descriptions of the integrity checks,
+// in the order they are run.
+func CheckNames() [][2]string {
+	var names [][2]string
+	for _, c := range galaxyChecks {
+		names = append(names, [2]string{c.name, c.description})
+	}
+	return names
+}
+
+// Check runs the named integrity checks, or all of them if no names are
+// given, and returns the problems found.
+func (g *GalaxyData) Check(names ...string) ([]Problem, error) {
+	selected := make(map[string]bool)
+	for _, name := range names {
+		found := false
+		for _, c := range galaxyChecks {
+			found = found || c.name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown check %q", name)
+		}
+		selected[name] = true
+	}
+
+	problems := []Problem{}
+	for _, c := range galaxyChecks {
+		if len(selected) != 0 && !selected[c.name] {
+			continue
+		}
+		var found []Problem
+		c.run(g, func(subject, format string, a ...interface{}) {
+			found = append(found, Problem{Check: c.name, Subject: subject, Message: fmt.Sprintf(format, a...)})
+		})
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].Subject < found[j].Subject
+		})
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+// checkReferences links the galaxy again and reports each reference
+// that doesn't point at anything.
+func checkReferences(g *GalaxyData, report func(string, string, ...interface{})) {
+	dangling, ok := g.link().(*DanglingError)
+	if !ok {
+		return
+	}
+	for _, ref := range dangling.References {
+		subject, message := "galaxy", ref
+		if i := strings.Index(ref, ": "); i != -1 {
+			subject, message = ref[:i], ref[i+2:]
+		}
+		report(subject, "%s", message)
+	}
+}
+
+func checkCounts(g *GalaxyData, report func(string, string, ...interface{})) {
+	planets, wormholes := 0, 0
+	for _, star := range g.AllStars() {
+		planets += len(star.Planets)
+		if star.WormHere {
+			wormholes++
+		}
+	}
+	if g.NumberOfStars != len(g.Stars) {
+		report("galaxy", "NumberOfStars is %d, but there are %d stars", g.NumberOfStars, len(g.Stars))
+	}
+	if g.NumberOfPlanets != planets {
+		report("galaxy", "NumberOfPlanets is %d, but there are %d planets", g.NumberOfPlanets, planets)
+	}
+	// both ends of a wormhole are flagged
+	if g.NumberOfWormHoles != wormholes/2 {
+		report("galaxy", "NumberOfWormHoles is %d, but there are %d wormholes", g.NumberOfWormHoles, wormholes/2)
+	}
+}
+
+func checkStarRadius(g *GalaxyData, report func(string, string, ...interface{})) {
+	// coordinates are offset by the radius so that they are never negative
+	r := g.Radius
+	for _, star := range g.AllStars() {
+		dx, dy, dz := star.X-r, star.Y-r, star.Z-r
+		if star.X < 0 || star.Y < 0 || star.Z < 0 || dx*dx+dy*dy+dz*dz >= r*r {
+			report(star.ID, "outside the galactic radius of %d parsecs", r)
+		}
+	}
+}
+
+func checkWormholes(g *GalaxyData, report func(string, string, ...interface{})) {
+	for _, star := range g.AllStars() {
+		if !star.WormHere {
+			continue
+		}
+		other := g.GetStarAt(star.WormX, star.WormY, star.WormZ)
+		if other == nil {
+			report(star.ID, "wormhole leads to %d %d %d, where there is no star", star.WormX, star.WormY, star.WormZ)
+		} else if other == star {
+			report(star.ID, "wormhole leads back to the same star")
+		} else if !other.WormHere {
+			report(star.ID, "wormhole leads to star %s, which has no wormhole", other.ID)
+		} else if other.WormX != star.X || other.WormY != star.Y || other.WormZ != star.Z {
+			report(star.ID, "wormhole leads to star %s, whose wormhole leads to %d %d %d", other.ID, other.WormX, other.WormY, other.WormZ)
+		}
+	}
+}
+
+func checkNumPlanets(g *GalaxyData, report func(string, string, ...interface{})) {
+	for _, star := range g.AllStars() {
+		if star.NumPlanets != len(star.Planets) {
+			report(star.ID, "NumPlanets is %d, but there are %d planets", star.NumPlanets, len(star.Planets))
+		}
+	}
+}
+
+func checkHomeSystems(g *GalaxyData, report func(string, string, ...interface{})) {
+	for _, star := range g.AllStars() {
+		if !star.HomeSystem {
+			continue
+		}
+		homes := 0
+		for _, planet := range star.Planets {
+			if planet.Special == IDEAL_HOME_PLANET {
+				homes++
+			}
+		}
+		if homes != 1 {
+			report(star.ID, "home system has %d ideal home planets", homes)
+		}
+	}
+}
+
+func checkGases(g *GalaxyData, report func(string, string, ...interface{})) {
+	for _, star := range g.AllStars() {
+		for pn, planet := range star.Planets {
+			total := 0
+			for _, gas := range planet.Gases {
+				total += gas.Percentage
+			}
+			if total != 0 && total != 100 {
+				report(fmt.Sprintf("%s #%d", star.ID, pn+1), "gases add up to %d percent", total)
+			}
+		}
+	}
+}
+
+func checkTechLevels(g *GalaxyData, report func(string, string, ...interface{})) {
+	for _, sp := range g.sortedSpecies() {
+		for tech := MI; tech <= BI; tech++ {
+			for _, level := range []struct {
+				name  string
+				value int
+			}{
+				{"tech level", sp.TechLevel[tech]},
+				{"initial tech level", sp.InitTechLevel[tech]},
+				{"tech knowledge", sp.TechKnowledge[tech]},
+			} {
+				if level.value < 0 || level.value > MAX_TECH_LEVEL {
+					report("SP "+sp.Name, "%s %s is %d, not between 0 and %d", tech_name[tech], level.name, level.value, MAX_TECH_LEVEL)
+				}
+			}
+		}
+	}
+}
+
+func checkNames(g *GalaxyData, report func(string, string, ...interface{})) {
+	// names are matched without regard to case, as they are in orders
+	species := make(map[string]string)
+	for _, sp := range g.sortedSpecies() {
+		if id, ok := species[strings.ToUpper(sp.Name)]; ok {
+			report("SP "+sp.Name, "species %s and %s have the same name", id, sp.ID)
+		}
+		species[strings.ToUpper(sp.Name)] = sp.ID
+
+		namplas := make(map[string]bool)
+		for _, nampla := range sp.Namplas {
+			if namplas[strings.ToUpper(nampla.Name)] {
+				report("SP "+sp.Name, "more than one planet is named PL %s", nampla.Name)
+			}
+			namplas[strings.ToUpper(nampla.Name)] = true
+		}
+		ships := make(map[string]bool)
+		for _, ship := range sp.Ships {
+			if ships[strings.ToUpper(ship.Name)] {
+				report("SP "+sp.Name, "more than one ship is named %s", ship.Name)
+			}
+			ships[strings.ToUpper(ship.Name)] = true
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import "testing"
+
+func TestCheckNamesIgnoresCase(t *testing.T) {
+	g := &GalaxyData{Species: map[string]*SpeciesData{
+		"01": {ID: "01", Number: 1, Name: "Rigel",
+			Namplas: []*NamedPlanetData{{Name: "Home"}, {Name: "HOME"}},
+			Ships:   []*ShipData{{Name: "Scout"}, {Name: "scout"}}},
+		"02": {ID: "02", Number: 2, Name: "rigel"},
+	}}
+	problems, err := g.Check("names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"names: SP Rigel: more than one planet is named PL HOME",
+		"names: SP Rigel: more than one ship is named scout",
+		"names: SP rigel: species 01 and 02 have the same name",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %v, want %v", problems, want)
+	}
+	for i, p := range problems {
+		if p.String() != want[i] {
+			t.Errorf("problem %d: got %q, want %q", i, p.String(), want[i])
+		}
+	}
+}
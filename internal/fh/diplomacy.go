@@ -0,0 +1,180 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// SpeciesSet is a set of species, keyed by species id. Only members
+// are stored.
+type SpeciesSet map[string]bool
+
+// Has returns true if the species id is in the set.
+func (s SpeciesSet) Has(id string) bool {
+	return s[id]
+}
+
+// Add puts a species id in the set.
+func (s *SpeciesSet) Add(id string) {
+	if *s == nil {
+		*s = make(SpeciesSet)
+	}
+	(*s)[id] = true
+}
+
+// Remove takes a species id out of the set.
+func (s SpeciesSet) Remove(id string) {
+	delete(s, id)
+}
+
+// IDs returns the species ids in the set, sorted.
+func (s SpeciesSet) IDs() []string {
+	var ids []string
+	for id, ok := range s {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// UnmarshalJSON unmarshals a json object to the set. Older files stored
+// the set as an array of flags indexed by species number, so arrays are
+// accepted too.
+func (s *SpeciesSet) UnmarshalJSON(b []byte) error {
+	var flags []bool
+	if err := json.Unmarshal(b, &flags); err == nil {
+		*s = make(SpeciesSet)
+		for number, ok := range flags {
+			if ok {
+				(*s)[fmt.Sprintf("%02d", number)] = true
+			}
+		}
+		return nil
+	}
+	var m map[string]bool
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*s = make(SpeciesSet)
+	for id, ok := range m {
+		if ok {
+			(*s)[id] = true
+		}
+	}
+	return nil
+}
+
+// Relation is the stance of one species toward another.
+type Relation int
+
+const (
+	NO_CONTACT Relation = iota // the species haven't met
+	UNALIGNED                  // met, but neither an ally nor an enemy
+	ALLIED
+	HOSTILE
+)
+
+func (r Relation) String() string {
+	switch r {
+	case NO_CONTACT:
+		return "not met"
+	case UNALIGNED:
+		return "neutral"
+	case ALLIED:
+		return "ally"
+	case HOSTILE:
+		return "enemy"
+	}
+	return "unknown"
+}
+
+// HasMet returns true if the species has made contact with the other.
+func (s *SpeciesData) HasMet(other *SpeciesData) bool {
+	return s.Contact.Has(other.ID)
+}
+
+// Meet records that the species has made contact with the other. It
+// returns false if they had already met.
+func (s *SpeciesData) Meet(other *SpeciesData) bool {
+	if other == s || s.HasMet(other) {
+		return false
+	}
+	s.Contact.Add(other.ID)
+	return true
+}
+
+// IsAlly returns true if the species considers the other an ally.
+func (s *SpeciesData) IsAlly(other *SpeciesData) bool {
+	return s.Ally.Has(other.ID)
+}
+
+// IsEnemy returns true if the species considers the other an enemy.
+func (s *SpeciesData) IsEnemy(other *SpeciesData) bool {
+	return s.Enemy.Has(other.ID)
+}
+
+// RelationTo returns the stance of the species toward the other. A
+// species can declare another an enemy before meeting it.
+func (s *SpeciesData) RelationTo(other *SpeciesData) Relation {
+	switch {
+	case s.IsEnemy(other):
+		return HOSTILE
+	case s.IsAlly(other):
+		return ALLIED
+	case s.HasMet(other):
+		return UNALIGNED
+	}
+	return NO_CONTACT
+}
+
+// SetRelation changes the stance of the species toward the other.
+// Only a species that has been met can become an ally.
+func (s *SpeciesData) SetRelation(other *SpeciesData, r Relation) error {
+	if other == s {
+		return fmt.Errorf("a species can't change its relations with itself")
+	}
+	switch r {
+	case ALLIED:
+		if !s.HasMet(other) {
+			return fmt.Errorf("SP %s has not met SP %s", s.Name, other.Name)
+		}
+		s.Ally.Add(other.ID)
+		s.Enemy.Remove(other.ID)
+	case HOSTILE:
+		s.Enemy.Add(other.ID)
+		s.Ally.Remove(other.ID)
+	case UNALIGNED:
+		s.Ally.Remove(other.ID)
+		s.Enemy.Remove(other.ID)
+	default:
+		return fmt.Errorf("invalid relation %q", r)
+	}
+	return nil
+}
+
+// Hostile returns true if species a is hostile to species b at a star
+// system: both are present and a has declared b an enemy.
+func (l *LocationIndex) Hostile(a, b *SpeciesData, x, y, z int) bool {
+	return a != b && a.IsEnemy(b) && l.IsPresent(a, x, y, z) && l.IsPresent(b, x, y, z)
+}
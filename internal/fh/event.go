@@ -0,0 +1,89 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+// EventMessage is an entry in the game's catalogue of messages: lore,
+// anomalies, scenario hints and the like. A message is attached to a
+// star system or a planet by setting its Message field to the ID of the
+// message. It is added to the report of a species the first time the
+// species visits or scans the star system. Naming a planet copies the
+// planet's message to the named planet and delivers it.
+type EventMessage struct {
+	ID          int        `json:"id"` // one-based index into the catalogue
+	Title       string     `json:"title,omitempty"`
+	Text        []string   `json:"text"`
+	DeliveredTo SpeciesSet `json:"delivered_to,omitempty"` // species that have seen the message
+}
+
+// AddEventMessage adds a message to the catalogue and returns its ID.
+func (g *GalaxyData) AddEventMessage(m *EventMessage) int {
+	m.ID = len(g.EventMessages) + 1
+	g.EventMessages = append(g.EventMessages, m)
+	return m.ID
+}
+
+// GetEventMessage returns the message in the catalogue with the given
+// ID, or nil.
+func (g *GalaxyData) GetEventMessage(id int) *EventMessage {
+	if id < 1 || id > len(g.EventMessages) {
+		return nil
+	}
+	return g.EventMessages[id-1]
+}
+
+// EventMessagesAt returns the messages seen by a ship arriving at a
+// location: the one attached to the star system and, if pn is not zero,
+// the one attached to that planet.
+func (g *GalaxyData) EventMessagesAt(x, y, z, pn int) []*EventMessage {
+	return g.eventMessages(x, y, z, func(n int) bool { return n == pn })
+}
+
+// EventMessagesInSystem returns the messages seen by a scan of a star
+// system: the one attached to the star system and the ones attached to
+// each of its planets.
+func (g *GalaxyData) EventMessagesInSystem(x, y, z int) []*EventMessage {
+	return g.eventMessages(x, y, z, func(int) bool { return true })
+}
+
+func (g *GalaxyData) eventMessages(x, y, z int, planetWanted func(pn int) bool) []*EventMessage {
+	star := g.GetStarAt(x, y, z)
+	if star == nil {
+		return nil
+	}
+	var list []*EventMessage
+	if m := g.GetEventMessage(star.Message); m != nil {
+		list = append(list, m)
+	}
+	for i, planet := range star.Planets {
+		if m := g.GetEventMessage(planet.Message); m != nil && planetWanted(i+1) {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// Deliver marks the message as seen by a species. It returns false if
+// the species had already seen it.
+func (m *EventMessage) Deliver(sp *SpeciesData) bool {
+	if m.DeliveredTo.Has(sp.ID) {
+		return false
+	}
+	m.DeliveredTo.Add(sp.ID)
+	return true
+}
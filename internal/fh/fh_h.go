@@ -206,8 +206,8 @@ type nampla_data struct {
 const PB = 0  /* Picketboat. */
 const CT = 1  /* Corvette. */
 const ES = 2  /* Escort. */
-const DD = 3  /* Destroyer. */
-const FG = 4  /* Frigate. */
+const FF = 3  /* Frigate. */
+const DD = 4  /* Destroyer. */
 const CL = 5  /* Light Cruiser. */
 const CS = 6  /* Strike Cruiser. */
 const CA = 7  /* Heavy Cruiser. */
@@ -23,9 +23,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sort"
 )
 
 type GalaxyData struct {
+	SchemaVersion     int    `json:"schema_version"` // version of the file layout, see SCHEMA_VERSION
 	ID                string `json:"id"`
 	Name              string `json:"name"`
 	Secret            string
@@ -38,7 +40,12 @@ type GalaxyData struct {
 	NumberOfWormHoles int
 	NumberOfPlanets   int
 	TurnNumber        int
+	Seed              uint64          `json:"seed"`          // seed for the random number streams
+	RNG               map[string]*RNG `json:"rng,omitempty"` // random number streams, by name
 	Stars             map[string]*StarData
+	Ledger            Ledger          `json:"ledger,omitempty"`         // interspecies transactions for the current turn
+	Messages          []*Message      `json:"messages,omitempty"`       // archive of every message sent between species
+	EventMessages     []*EventMessage `json:"event_messages,omitempty"` // catalogue of messages attached to stars and planets
 	Templates         struct {
 		Homes [10][]*PlanetData
 	}
@@ -57,15 +64,21 @@ type Player struct {
 	Species      string `json:"species"`
 }
 
-func GenerateGalaxy(setupData *SetupData) (*GalaxyData, error) {
+// GenerateGalaxy creates the stars and planets for a new game. All of
+// the random numbers come from the galaxy's generation stream, so the
+// same setup and seed always create the same galaxy.
+func GenerateGalaxy(setupData *SetupData, seed uint64) (*GalaxyData, error) {
 	galaxy := &GalaxyData{
-		ID:      setupData.Galaxy.Name,
-		Name:    setupData.Galaxy.Name,
-		Secret:  "your-private-key-belongs-here",
-		Players: make(map[string]*Player),
-		Species: make(map[string]*SpeciesData),
-		Stars:   make(map[string]*StarData),
+		SchemaVersion: SCHEMA_VERSION,
+		ID:            setupData.Galaxy.Name,
+		Name:          setupData.Galaxy.Name,
+		Secret:        "your-private-key-belongs-here",
+		Players:       make(map[string]*Player),
+		Species:       make(map[string]*SpeciesData),
+		Stars:         make(map[string]*StarData),
+		Seed:          seed,
 	}
+	r := galaxy.Stream(GENERATION_STREAM)
 	galaxy.Translate.EmailToID = make(map[string]string)
 	galaxy.Translate.SpeciesNameToID = make(map[string]string)
 	galaxy.Translate.XYZToID = make(map[string]string)
@@ -150,7 +163,7 @@ func GenerateGalaxy(setupData *SetupData) (*GalaxyData, error) {
 	// randomly place stars
 	for num_stars := 0; num_stars < desired_num_stars; {
 		// generate coordinates randomly
-		x, y, z := rnd(galactic_diameter)-1, rnd(galactic_diameter)-1, rnd(galactic_diameter)-1
+		x, y, z := r.Roll(galactic_diameter)-1, r.Roll(galactic_diameter)-1, r.Roll(galactic_diameter)-1
 		// verify the coordinates are within the galactic boundary
 		real_x, real_y, real_z := x-galactic_radius, y-galactic_radius, z-galactic_radius
 		sq_distance_from_center := (real_x * real_x) + (real_y * real_y) + (real_z * real_z)
@@ -162,7 +175,7 @@ func GenerateGalaxy(setupData *SetupData) (*GalaxyData, error) {
 			continue
 		}
 		// add the star at these coordinates
-		star, err := GenerateStar(x, y, z, galaxy.DNumSpecies)
+		star, err := GenerateStar(r, x, y, z, galaxy.DNumSpecies)
 		if err != nil {
 			return nil, err
 		}
@@ -180,13 +193,13 @@ func GenerateGalaxy(setupData *SetupData) (*GalaxyData, error) {
 	//	minWormholeLength = 20
 	//}
 	for _, star := range galaxy.AllStars() {
-		if star.HomeSystem || star.WormHere || rnd(100) < 92 {
+		if star.HomeSystem || star.WormHere || r.Roll(100) < 92 {
 			continue
 		}
 
 		// we want to put a wormhole here if we can find a star at least that minimum distance away that doesn't already have a worm hole
 		var worm_star *StarData
-		for k, f := 0, rnd(desired_num_stars); k < desired_num_stars && worm_star == nil; k++ {
+		for k, f := 0, r.Roll(desired_num_stars); k < desired_num_stars && worm_star == nil; k++ {
 			ps := galaxy.Stars[galaxy.Translate.IndexToStarID[(k+f)%len(galaxy.Translate.IndexToStarID)]]
 			if ps == star || ps.HomeSystem || ps.WormHere {
 				continue
@@ -227,19 +240,62 @@ func GenerateGalaxy(setupData *SetupData) (*GalaxyData, error) {
 	return galaxy, nil
 }
 
-// GetGalaxy loads data from a JSON file.
+// GetGalaxy loads data from a JSON file. Files written with an older
+// schema are upgraded in memory; the file itself isn't changed.
+//
+// If the file has references that don't point at anything, the linked
+// galaxy is returned along with a wrapped *DanglingError, so that the
+// caller can decide whether the problem is fatal. Use errors.As to find
+// it.
 func GetGalaxy(name string) (*GalaxyData, error) {
 	data, err := ioutil.ReadFile(name)
 	if err != nil {
 		return nil, err
 	}
+	data, _, err = upgradeGalaxy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var galaxy GalaxyData
+	if err := json.Unmarshal(data, &galaxy); err != nil {
+		return nil, err
+	}
+	if err := galaxy.link(); err != nil {
+		return &galaxy, fmt.Errorf("%s: %w", name, err)
+	}
+	return &galaxy, nil
+}
+
+// Clone returns a deep copy of the galaxy. Like GetGalaxy, it returns
+// the copy along with a *DanglingError if the galaxy has references that
+// don't point at anything.
+func (g *GalaxyData) Clone() (*GalaxyData, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
 	var galaxy GalaxyData
 	if err := json.Unmarshal(data, &galaxy); err != nil {
 		return nil, err
 	}
+	if err := galaxy.link(); err != nil {
+		return &galaxy, err
+	}
 	return &galaxy, nil
 }
 
+// sortedSpecies returns the species sorted by species number.
+func (g *GalaxyData) sortedSpecies() []*SpeciesData {
+	var species []*SpeciesData
+	for _, sp := range g.Species {
+		species = append(species, sp)
+	}
+	sort.Slice(species, func(i, j int) bool {
+		return species[i].Number < species[j].Number
+	})
+	return species
+}
+
 func (g *GalaxyData) AllStars() []*StarData {
 	stars := g.allStars
 	if len(stars) != len(g.Translate.IndexToStarID) {
@@ -296,6 +352,15 @@ func (g *GalaxyData) GetStarAt(x, y, z int) *StarData {
 	return g.Stars[XYZToID(x, y, z)]
 }
 
+// GetPlanet returns the planet at the given location. Planet numbers start at one.
+func (g *GalaxyData) GetPlanet(x, y, z, pn int) *PlanetData {
+	star := g.GetStarAt(x, y, z)
+	if star == nil || pn < 1 || pn > len(star.Planets) {
+		return nil
+	}
+	return star.Planets[pn-1]
+}
+
 func (g *GalaxyData) GetStarByID(id string) *StarData {
 	return g.Stars[id]
 }
@@ -437,6 +502,7 @@ func (g *GalaxyData) Scan(w io.Writer, x, y, z int) error {
 }
 
 func (g *GalaxyData) Write(filename string) error {
+	g.SchemaVersion = SCHEMA_VERSION
 	if b, err := json.MarshalIndent(g, "  ", "  "); err != nil {
 		return err
 	} else if err := ioutil.WriteFile(filename, b, 0644); err != nil {
@@ -0,0 +1,145 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Hash returns the SHA-256 of the canonical JSON encoding of the galaxy.
+// The encoding is canonical because encoding/json writes map keys in
+// sorted order, so Stars and Species always come out the same way, and
+// every slice in the galaxy is kept in a fixed order by the phases.
+func (g *GalaxyData) Hash() (string, error) {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FirstDifference compares two galaxies and describes the first species,
+// named planet, ship, star or planet that differs between them. Species
+// are compared first, in species number order, then stars in id order,
+// then everything else. It returns an empty string if the galaxies are
+// the same.
+func FirstDifference(a, b *GalaxyData) (string, error) {
+	for _, spA := range a.sortedSpecies() {
+		spB := b.Species[spA.ID]
+		if spB == nil {
+			return fmt.Sprintf("SP %s: missing from the second galaxy", spA.Name), nil
+		}
+		for i, nampla := range spA.Namplas {
+			if i >= len(spB.Namplas) {
+				return fmt.Sprintf("SP %s: PL %s: missing from the second galaxy", spA.Name, nampla.Name), nil
+			} else if same, err := sameJSON(nampla, spB.Namplas[i]); err != nil {
+				return "", err
+			} else if !same {
+				return fmt.Sprintf("SP %s: PL %s", spA.Name, nampla.Name), nil
+			}
+		}
+		if len(spB.Namplas) > len(spA.Namplas) {
+			return fmt.Sprintf("SP %s: PL %s: missing from the first galaxy", spA.Name, spB.Namplas[len(spA.Namplas)].Name), nil
+		}
+		for i, ship := range spA.Ships {
+			if i >= len(spB.Ships) {
+				return fmt.Sprintf("SP %s: %s: missing from the second galaxy", spA.Name, ship.Display()), nil
+			} else if same, err := sameJSON(ship, spB.Ships[i]); err != nil {
+				return "", err
+			} else if !same {
+				return fmt.Sprintf("SP %s: %s", spA.Name, ship.Display()), nil
+			}
+		}
+		if len(spB.Ships) > len(spA.Ships) {
+			return fmt.Sprintf("SP %s: %s: missing from the first galaxy", spA.Name, spB.Ships[len(spA.Ships)].Display()), nil
+		}
+		if same, err := sameJSON(spA, spB); err != nil {
+			return "", err
+		} else if !same {
+			return fmt.Sprintf("SP %s", spA.Name), nil
+		}
+	}
+	for _, sp := range b.sortedSpecies() {
+		if a.Species[sp.ID] == nil {
+			return fmt.Sprintf("SP %s: missing from the first galaxy", sp.Name), nil
+		}
+	}
+
+	for _, id := range a.sortedStarIDs() {
+		starA, starB := a.Stars[id], b.Stars[id]
+		if starB == nil {
+			return fmt.Sprintf("star %d %d %d: missing from the second galaxy", starA.X, starA.Y, starA.Z), nil
+		}
+		for i, planet := range starA.Planets {
+			if i >= len(starB.Planets) {
+				return fmt.Sprintf("star %d %d %d: planet %d: missing from the second galaxy", starA.X, starA.Y, starA.Z, i+1), nil
+			} else if same, err := sameJSON(planet, starB.Planets[i]); err != nil {
+				return "", err
+			} else if !same {
+				return fmt.Sprintf("star %d %d %d: planet %d", starA.X, starA.Y, starA.Z, i+1), nil
+			}
+		}
+		if same, err := sameJSON(starA, starB); err != nil {
+			return "", err
+		} else if !same {
+			return fmt.Sprintf("star %d %d %d", starA.X, starA.Y, starA.Z), nil
+		}
+	}
+	for _, id := range b.sortedStarIDs() {
+		if star := b.Stars[id]; a.Stars[id] == nil {
+			return fmt.Sprintf("star %d %d %d: missing from the first galaxy", star.X, star.Y, star.Z), nil
+		}
+	}
+
+	if same, err := sameJSON(a, b); err != nil {
+		return "", err
+	} else if !same {
+		return "galaxy: turn number, ledger, messages or random number state", nil
+	}
+	return "", nil
+}
+
+// sortedStarIDs returns the ids of the stars in the galaxy, in order.
+func (g *GalaxyData) sortedStarIDs() []string {
+	var ids []string
+	for id := range g.Stars {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sameJSON returns true if two values have the same JSON encoding.
+func sameJSON(a, b interface{}) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ja, jb), nil
+}
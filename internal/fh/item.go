@@ -0,0 +1,195 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Item is a kind of unit that can be built, stored on planets and
+// carried by ships, from RM (raw material units) to GU9 (Mark-9 guns).
+// X1 through X5 are reserved and can't be built.
+type Item int
+
+// items holds the rules for each item. Cost is in economic units and
+// carry is the cargo space used by one unit. A species needs level
+// in tech to build the item.
+var items = [MAX_ITEMS]struct {
+	abbr, name  string
+	cost, carry int
+	tech, level int
+}{
+	RM:  {"RM", "Raw Material Unit", 1, 1, MI, 1},
+	PD:  {"PD", "Planetary Defense Unit", 1, 3, ML, 1},
+	SU:  {"SU", "Starbase Unit", 110, 20, MA, 20},
+	DR:  {"DR", "Damage Repair Unit", 50, 1, MA, 30},
+	CU:  {"CU", "Colonist Unit", 1, 1, LS, 1},
+	IU:  {"IU", "Colonial Mining Unit", 1, 1, MI, 1},
+	AU:  {"AU", "Colonial Manufacturing Unit", 1, 1, MA, 1},
+	FS:  {"FS", "Fail-Safe Jump Unit", 25, 1, GV, 20},
+	JP:  {"JP", "Jump Portal Unit", 100, 10, GV, 25},
+	FM:  {"FM", "Forced Misjump Unit", 100, 5, GV, 30},
+	FJ:  {"FJ", "Forced Jump Unit", 125, 5, GV, 40},
+	GT:  {"GT", "Gravitic Telescope Unit", 500, 20, GV, 50},
+	FD:  {"FD", "Field Distortion Unit", 50, 1, LS, 20},
+	TP:  {"TP", "Terraforming Plant", 50000, 100, BI, 40},
+	GW:  {"GW", "Germ Warfare Bomb", 1000, 100, BI, 50},
+	SG1: {"SG1", "Mark-1 Shield Generator", 250, 5, LS, 10},
+	SG2: {"SG2", "Mark-2 Shield Generator", 500, 10, LS, 20},
+	SG3: {"SG3", "Mark-3 Shield Generator", 750, 15, LS, 30},
+	SG4: {"SG4", "Mark-4 Shield Generator", 1000, 20, LS, 40},
+	SG5: {"SG5", "Mark-5 Shield Generator", 1250, 25, LS, 50},
+	SG6: {"SG6", "Mark-6 Shield Generator", 1500, 30, LS, 60},
+	SG7: {"SG7", "Mark-7 Shield Generator", 1750, 35, LS, 70},
+	SG8: {"SG8", "Mark-8 Shield Generator", 2000, 40, LS, 80},
+	SG9: {"SG9", "Mark-9 Shield Generator", 2250, 45, LS, 90},
+	GU1: {"GU1", "Mark-1 Gun Unit", 250, 5, ML, 10},
+	GU2: {"GU2", "Mark-2 Gun Unit", 500, 10, ML, 20},
+	GU3: {"GU3", "Mark-3 Gun Unit", 750, 15, ML, 30},
+	GU4: {"GU4", "Mark-4 Gun Unit", 1000, 20, ML, 40},
+	GU5: {"GU5", "Mark-5 Gun Unit", 1250, 25, ML, 50},
+	GU6: {"GU6", "Mark-6 Gun Unit", 1500, 30, ML, 60},
+	GU7: {"GU7", "Mark-7 Gun Unit", 1750, 35, ML, 70},
+	GU8: {"GU8", "Mark-8 Gun Unit", 2000, 40, ML, 80},
+	GU9: {"GU9", "Mark-9 Gun Unit", 2250, 45, ML, 90},
+	X1:  {"X1", "X1 Unit", 9999, 9999, -1, 999},
+	X2:  {"X2", "X2 Unit", 9999, 9999, -1, 999},
+	X3:  {"X3", "X3 Unit", 9999, 9999, -1, 999},
+	X4:  {"X4", "X4 Unit", 9999, 9999, -1, 999},
+	X5:  {"X5", "X5 Unit", 9999, 9999, -1, 999},
+}
+
+// AllItems returns every item that can be built, RM first.
+// The unassigned items X1 through X5 are not included.
+func AllItems() []Item {
+	var list []Item
+	for i := range items {
+		if Item(i).Assigned() {
+			list = append(list, Item(i))
+		}
+	}
+	return list
+}
+
+// LookupItem returns the item for an abbreviation, e.g. CU or SG3.
+// Case is ignored.
+func LookupItem(abbr string) (Item, bool) {
+	for i, item := range items {
+		if strings.EqualFold(item.abbr, abbr) {
+			return Item(i), true
+		}
+	}
+	return 0, false
+}
+
+// LookupItemByName returns the item for a display name, e.g.
+// "Colonist Unit". Case is ignored and a trailing "s" is allowed.
+func LookupItemByName(name string) (Item, bool) {
+	for i, item := range items {
+		if strings.EqualFold(item.name, name) || strings.EqualFold(item.name+"s", name) {
+			return Item(i), true
+		}
+	}
+	return 0, false
+}
+
+func (i Item) valid() bool {
+	return i >= 0 && i < MAX_ITEMS
+}
+
+// Assigned returns false for the reserved items X1 through X5.
+func (i Item) Assigned() bool {
+	return i.valid() && i < X1
+}
+
+// Abbr returns the abbreviation used in orders and reports, e.g. CU.
+func (i Item) Abbr() string {
+	if !i.valid() {
+		return "??"
+	}
+	return items[i].abbr
+}
+
+func (i Item) String() string {
+	if !i.valid() {
+		return "Unknown Item"
+	}
+	return items[i].name
+}
+
+// Cost returns the cost, in economic units, of one unit of the item.
+func (i Item) Cost() int {
+	if !i.valid() {
+		return 0
+	}
+	return items[i].cost
+}
+
+// CarryCapacity returns the cargo space used by one unit of the item.
+func (i Item) CarryCapacity() int {
+	if !i.valid() {
+		return 0
+	}
+	return items[i].carry
+}
+
+// TechRequired returns the tech and the level in that tech that a
+// species needs to build the item. The reserved items return a tech
+// of -1.
+func (i Item) TechRequired() (tech, level int) {
+	if !i.valid() {
+		return -1, 999
+	}
+	return items[i].tech, items[i].level
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (i Item) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(i.Abbr())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmarshals a quoted json string to the enum value.
+// Item numbers are accepted too.
+func (i *Item) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		if !Item(n).valid() {
+			return fmt.Errorf("invalid Item %d", n)
+		}
+		*i = Item(n)
+		return nil
+	}
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	item, ok := LookupItem(j)
+	if !ok {
+		return fmt.Errorf("invalid Item %q", string(b))
+	}
+	*i = item
+	return nil
+}
@@ -0,0 +1,48 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+// DistanceSquared returns the square of the distance between two locations.
+func DistanceSquared(x1, y1, z1, x2, y2, z2 int) int {
+	dx, dy, dz := x1-x2, y1-y2, z1-z2
+	return dx*dx + dy*dy + dz*dz
+}
+
+// MishapChance returns the chance, in hundredths of a percent, that a jump
+// ends in a mishap. The base chance is the squared distance of the jump
+// divided by the gravitics tech level of the species. Each year of age
+// takes away two percent of the remaining chance of success.
+func MishapChance(distanceSquared, gv, age int) int {
+	if gv < 1 {
+		gv = 1
+	}
+	mishap_chance := (100 * distanceSquared) / gv
+	if mishap_chance > 10000 {
+		return 10000
+	} else if age > 0 {
+		/* Add aging effect. */
+		success_chance := 10000 - mishap_chance
+		success_chance -= (2 * age * success_chance) / 100
+		if success_chance < 0 {
+			success_chance = 0
+		}
+		mishap_chance = 10000 - success_chance
+	}
+	return mishap_chance
+}
@@ -0,0 +1,875 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// The original C server writes its data as raw structs, so the files use
+// the host's field widths and alignment. A char is 1 byte, a short is 2
+// bytes and an int is 4 bytes. A long is 4 bytes on the 32-bit builds
+// that fh.h assumes, and 8 bytes on most 64-bit builds.
+
+// LEGACY_UNUSED is the planet number the C server gives to a deleted
+// named planet or ship. The slot stays in the file until it is reused.
+const LEGACY_UNUSED = 99
+
+// LEGACY_HOME_PLANET is the nampla index used by loading_point and
+// unloading_point for the home planet.
+const LEGACY_HOME_PLANET = 9999
+
+// legacyDecoder reads the fields of a C struct in order, skipping the
+// padding the compiler adds to align each field.
+type legacyDecoder struct {
+	name     string // for error messages
+	buf      []byte
+	off      int
+	longSize int
+	err      error
+}
+
+func newLegacyDecoder(name string, buf []byte, longSize int) *legacyDecoder {
+	return &legacyDecoder{name: name, buf: buf, longSize: longSize}
+}
+
+// align skips padding up to the next multiple of n.
+func (d *legacyDecoder) align(n int) {
+	if rem := d.off % n; rem != 0 {
+		d.off += n - rem
+	}
+}
+
+// next returns the next n bytes, or nil once the data runs out.
+func (d *legacyDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	} else if d.off+n > len(d.buf) {
+		d.err = fmt.Errorf("%s: unexpected end of data at offset %d", d.name, d.off)
+		return nil
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *legacyDecoder) char() int {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return int(int8(b[0]))
+}
+
+func (d *legacyDecoder) short() int {
+	d.align(2)
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int(int16(binary.LittleEndian.Uint16(b)))
+}
+
+func (d *legacyDecoder) int() int {
+	d.align(4)
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int(int32(binary.LittleEndian.Uint32(b)))
+}
+
+func (d *legacyDecoder) long() int {
+	if d.longSize != 8 {
+		return d.int()
+	}
+	d.align(8)
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int(int64(binary.LittleEndian.Uint64(b)))
+}
+
+// str reads a fixed size, NUL terminated character array.
+func (d *legacyDecoder) str(n int) string {
+	b := d.next(n)
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// skip reads a character array that isn't used, such as padding.
+func (d *legacyDecoder) skip(n int) {
+	d.next(n)
+}
+
+// bits reads an array of long words where bit n-1 is set for species n.
+func (d *legacyDecoder) bits() SpeciesSet {
+	set := make(SpeciesSet)
+	for word := 0; word < NUM_CONTACT_WORDS; word++ {
+		w := uint32(d.long())
+		for bit := 0; bit < 32; bit++ {
+			if w&(1<<bit) != 0 {
+				set.Add(fmt.Sprintf("%02d", word*32+bit+1))
+			}
+		}
+	}
+	return set
+}
+
+// end skips the padding at the end of a struct. Structs that contain a
+// long are aligned on a long, the rest on an int.
+func (d *legacyDecoder) end(hasLong bool) {
+	if hasLong && d.longSize == 8 {
+		d.align(8)
+	} else {
+		d.align(4)
+	}
+}
+
+// ImportLegacy reads a game from the binary files written by the C
+// server: galaxy.dat, stars.dat, planets.dat and one spNN.dat for each
+// species still in the game. longSize is the size in bytes of a C long
+// on the machine that wrote the files, either 4 or 8. The C files don't
+// have a seed for the random number streams, so the caller provides one;
+// the same files and seed always give the same galaxy.
+func ImportLegacy(dir string, longSize int, seed uint64) (*GalaxyData, error) {
+	if longSize != 4 && longSize != 8 {
+		return nil, fmt.Errorf("long size must be 4 or 8, not %d", longSize)
+	}
+
+	g := &GalaxyData{
+		SchemaVersion: SCHEMA_VERSION,
+		Secret:        "your-private-key-belongs-here",
+		Players:       make(map[string]*Player),
+		Species:       make(map[string]*SpeciesData),
+		Stars:         make(map[string]*StarData),
+		Seed:          seed,
+	}
+	g.Translate.EmailToID = make(map[string]string)
+	g.Translate.SpeciesNameToID = make(map[string]string)
+	g.Translate.XYZToID = make(map[string]string)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "galaxy.dat"))
+	if err != nil {
+		return nil, err
+	}
+	d := newLegacyDecoder("galaxy.dat", data, longSize)
+	g.DNumSpecies = d.int()
+	g.NumSpecies = d.int()
+	g.Radius = d.int()
+	g.TurnNumber = d.int()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	// the planets are read first so that each star can take its own
+	planets, err := importLegacyPlanets(filepath.Join(dir, "planets.dat"), longSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.importLegacyStars(filepath.Join(dir, "stars.dat"), longSize, planets); err != nil {
+		return nil, err
+	}
+
+	for number := 1; number <= g.NumSpecies; number++ {
+		name := filepath.Join(dir, fmt.Sprintf("sp%02d.dat", number))
+		data, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			// the species has been eliminated
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		sp, err := importLegacySpecies(newLegacyDecoder(filepath.Base(name), data, longSize))
+		if err != nil {
+			return nil, err
+		}
+		sp.ID, sp.Number = fmt.Sprintf("%02d", number), number
+		g.Species[sp.ID] = sp
+		g.Translate.SpeciesNameToID[sp.Name] = sp.ID
+	}
+
+	if err := g.link(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func importLegacyPlanets(name string, longSize int) ([]*PlanetData, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	d := newLegacyDecoder(filepath.Base(name), data, longSize)
+	num_planets := d.int()
+	if d.err == nil && (num_planets < 0 || num_planets > MAX_PLANETS) {
+		return nil, fmt.Errorf("%s: invalid number of planets %d", d.name, num_planets)
+	}
+	planets := make([]*PlanetData, num_planets)
+	for i := range planets {
+		planet := &PlanetData{}
+		planet.TemperatureClass = d.char()
+		planet.PressureClass = d.char()
+		planet.Special = PlanetSpecialType(d.char())
+		d.char() // reserved1
+		var gas, gas_percent [4]int
+		for j := range gas {
+			gas[j] = d.char()
+		}
+		for j := range gas_percent {
+			gas_percent[j] = d.char()
+		}
+		for j := range gas {
+			if gas[j] != 0 {
+				planet.Gases = append(planet.Gases, &GasData{Type: GasType(gas[j]), Percentage: gas_percent[j]})
+			}
+		}
+		d.short() // reserved2
+		planet.Diameter = d.short()
+		planet.Gravity = d.short()
+		planet.MiningDifficulty = d.short()
+		planet.EconEfficiency = d.short()
+		planet.MDIncrease = d.short()
+		planet.Message = d.long()
+		d.long() // reserved3
+		d.long() // reserved4
+		d.long() // reserved5
+		d.end(true)
+		planets[i] = planet
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return planets, nil
+}
+
+func (g *GalaxyData) importLegacyStars(name string, longSize int, planets []*PlanetData) error {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	d := newLegacyDecoder(filepath.Base(name), data, longSize)
+	num_stars := d.int()
+	if d.err == nil && (num_stars < 0 || num_stars > MAX_STARS) {
+		return fmt.Errorf("%s: invalid number of stars %d", d.name, num_stars)
+	}
+	for i := 0; i < num_stars && d.err == nil; i++ {
+		star := &StarData{SystemNumber: i + 1}
+		star.X, star.Y, star.Z = d.char(), d.char(), d.char()
+		star.Type = StarType(d.char())
+		star.Color = StarColor(d.char())
+		star.Size = d.char()
+		star.NumPlanets = d.char()
+		star.HomeSystem = d.char() != 0
+		star.WormHere = d.char() != 0
+		star.WormX, star.WormY, star.WormZ = d.char(), d.char(), d.char()
+		d.short() // reserved1
+		d.short() // reserved2
+		star.PlanetIndex = d.short()
+		star.Message = d.long()
+		star.VisitedBy = d.bits()
+		d.long() // reserved3
+		d.long() // reserved4
+		d.long() // reserved5
+		d.end(true)
+		if d.err != nil {
+			break
+		}
+
+		star.ID = XYZToID(star.X, star.Y, star.Z)
+		if _, ok := g.Stars[star.ID]; ok {
+			return fmt.Errorf("%s: duplicate star at %d %d %d", d.name, star.X, star.Y, star.Z)
+		} else if star.PlanetIndex < 0 || star.NumPlanets < 0 || star.PlanetIndex+star.NumPlanets > len(planets) {
+			return fmt.Errorf("%s: star at %d %d %d has planets outside planets.dat", d.name, star.X, star.Y, star.Z)
+		}
+		for pn, planet := range planets[star.PlanetIndex : star.PlanetIndex+star.NumPlanets] {
+			planet.ID = fmt.Sprintf("%s-%02d", star.ID, pn+1)
+			star.Planets = append(star.Planets, planet)
+		}
+		g.Stars[star.ID] = star
+		g.Translate.IndexToStarID = append(g.Translate.IndexToStarID, star.ID)
+		g.Translate.XYZToID[star.ID] = star.ID
+		g.NumberOfPlanets += star.NumPlanets
+		if star.WormHere {
+			g.NumberOfWormHoles++
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	g.NumberOfStars = len(g.Stars)
+	// both ends of a natural wormhole are flagged
+	g.NumberOfWormHoles /= 2
+	return nil
+}
+
+func importLegacySpecies(d *legacyDecoder) (*SpeciesData, error) {
+	sp := &SpeciesData{}
+	sp.Name = d.str(32)
+	sp.GovtName = d.str(32)
+	sp.GovtType = d.str(32)
+	sp.X, sp.Y, sp.Z, sp.PN = d.char(), d.char(), d.char(), d.char()
+	sp.RequiredGas = GasType(d.char())
+	sp.RequiredGasMin = d.char()
+	sp.RequiredGasMax = d.char()
+	d.char() // reserved5
+	for i := 0; i < 6; i++ {
+		if gas := d.char(); gas != 0 {
+			sp.NeutralGas = append(sp.NeutralGas, GasType(gas))
+		}
+	}
+	for i := 0; i < 6; i++ {
+		if gas := d.char(); gas != 0 {
+			sp.PoisonGas = append(sp.PoisonGas, GasType(gas))
+		}
+	}
+	sp.AutoOrders = d.char() != 0
+	d.char()  // reserved3
+	d.short() // reserved4
+	for i := range sp.TechLevel {
+		sp.TechLevel[i] = d.short()
+	}
+	for i := range sp.InitTechLevel {
+		sp.InitTechLevel[i] = d.short()
+	}
+	for i := range sp.TechKnowledge {
+		sp.TechKnowledge[i] = d.short()
+	}
+	num_namplas := d.int()
+	num_ships := d.int()
+	for i := range sp.TechEps {
+		sp.TechEps[i] = d.long()
+	}
+	sp.HPOriginalBase = d.long()
+	sp.EconUnits = d.long()
+	sp.FleetCost = d.long()
+	sp.FleetPercentCost = d.long()
+	sp.Contact = d.bits()
+	sp.Ally = d.bits()
+	sp.Enemy = d.bits()
+	d.skip(12) // padding
+	d.end(true)
+	if d.err != nil {
+		return nil, d.err
+	} else if num_namplas < 1 || num_namplas > MAX_LOCATIONS || num_ships < 0 || num_ships > MAX_LOCATIONS {
+		return nil, fmt.Errorf("%s: invalid counts: %d namplas, %d ships", d.name, num_namplas, num_ships)
+	}
+
+	// ships refer to named planets by their index in the file, which
+	// includes the unused slots
+	var names []string
+	for i := 0; i < num_namplas && d.err == nil; i++ {
+		nampla := importLegacyNampla(d)
+		names = append(names, nampla.Name)
+		if nampla.PN != LEGACY_UNUSED {
+			sp.Namplas = append(sp.Namplas, nampla)
+		}
+	}
+	pointName := func(index int) string {
+		if index == LEGACY_HOME_PLANET {
+			return names[0]
+		} else if index > 0 && index < len(names) {
+			return names[index]
+		}
+		return ""
+	}
+	for i := 0; i < num_ships && d.err == nil; i++ {
+		ship, loading_point, unloading_point := importLegacyShip(d)
+		ship.LoadingPoint, ship.UnloadingPoint = pointName(loading_point), pointName(unloading_point)
+		if ship.PN != LEGACY_UNUSED {
+			sp.Ships = append(sp.Ships, ship)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return sp, nil
+}
+
+func importLegacyNampla(d *legacyDecoder) *NamedPlanetData {
+	nampla := &NamedPlanetData{}
+	nampla.Name = d.str(32)
+	nampla.X, nampla.Y, nampla.Z, nampla.PN = d.char(), d.char(), d.char(), d.char()
+	nampla.Status = uint64(uint8(d.char()))
+	d.char() // reserved1
+	nampla.Hiding = d.char() != 0
+	nampla.Hidden = d.char() != 0
+	d.short() // reserved2
+	nampla.PlanetIndex = d.short()
+	nampla.SiegeEff = d.short()
+	nampla.Shipyards = d.short()
+	d.int() // reserved4
+	nampla.IUsNeeded = d.int()
+	nampla.AUsNeeded = d.int()
+	nampla.AutoIUs = d.int()
+	nampla.AutoAUs = d.int()
+	d.int() // reserved5
+	nampla.IUsToInstall = d.int()
+	nampla.AUsToInstall = d.int()
+	nampla.MIBase = d.long()
+	nampla.MABase = d.long()
+	nampla.PopUnits = d.long()
+	for i := range nampla.ItemQuantity {
+		nampla.ItemQuantity[i] = d.long()
+	}
+	d.long() // reserved6
+	nampla.UseOnAmbush = d.long()
+	nampla.Message = d.long()
+	nampla.Special = d.long()
+	d.skip(28) // padding
+	d.end(true)
+	return nampla
+}
+
+// importLegacyShip returns the ship along with the nampla indexes of its
+// loading and unloading points.
+func importLegacyShip(d *legacyDecoder) (*ShipData, int, int) {
+	ship := &ShipData{}
+	ship.Name = d.str(32)
+	ship.X, ship.Y, ship.Z, ship.PN = d.char(), d.char(), d.char(), d.char()
+	ship.Status = ShipStatus(d.char())
+	ship.Type = d.char()
+	ship.DestX, ship.DestY, ship.DestZ = d.char(), d.char(), d.char()
+	ship.JustJumped = d.char() != 0
+	ship.ArrivedViaWormhole = d.char() != 0
+	d.char()  // reserved1
+	d.short() // reserved2
+	d.short() // reserved3
+	ship.Class = ShipClass(d.short())
+	ship.Tonnage = d.short()
+	for i := range ship.ItemQuantity {
+		ship.ItemQuantity[i] = d.short()
+	}
+	ship.Age = d.short()
+	ship.RemainingCost = d.short()
+	d.short() // reserved4
+	loading_point := d.short()
+	unloading_point := d.short()
+	ship.Special = d.long()
+	d.skip(28) // padding
+	d.end(true)
+	return ship, loading_point, unloading_point
+}
+
+// legacyEncoder writes the fields of a C struct in order, adding the
+// padding the compiler would add to align each field. A value that
+// doesn't fit in its field is an error rather than being truncated.
+type legacyEncoder struct {
+	name     string // for error messages
+	buf      bytes.Buffer
+	longSize int
+	err      error
+}
+
+func newLegacyEncoder(name string, longSize int) *legacyEncoder {
+	return &legacyEncoder{name: name, longSize: longSize}
+}
+
+// align adds padding up to the next multiple of n.
+func (e *legacyEncoder) align(n int) {
+	for e.buf.Len()%n != 0 {
+		e.buf.WriteByte(0)
+	}
+}
+
+// fits records an error if v is outside the range of a field.
+func (e *legacyEncoder) fits(v, min, max int, field string) bool {
+	if e.err == nil && (v < min || v > max) {
+		e.err = fmt.Errorf("%s: %s: value %d does not fit in the field", e.name, field, v)
+	}
+	return e.err == nil
+}
+
+func (e *legacyEncoder) char(v int, field string) {
+	if e.fits(v, -128, 127, field) {
+		e.buf.WriteByte(byte(int8(v)))
+	}
+}
+
+func (e *legacyEncoder) bool(v bool, field string) {
+	if v {
+		e.char(TRUE, field)
+	} else {
+		e.char(FALSE, field)
+	}
+}
+
+func (e *legacyEncoder) short(v int, field string) {
+	e.align(2)
+	if e.fits(v, -1<<15, 1<<15-1, field) {
+		var b [2]byte
+		binary.LittleEndian.PutUint16(b[:], uint16(int16(v)))
+		e.buf.Write(b[:])
+	}
+}
+
+func (e *legacyEncoder) int(v int, field string) {
+	e.align(4)
+	if e.fits(v, -1<<31, 1<<31-1, field) {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], uint32(int32(v)))
+		e.buf.Write(b[:])
+	}
+}
+
+func (e *legacyEncoder) long(v int, field string) {
+	if e.longSize != 8 {
+		e.int(v, field)
+		return
+	}
+	e.align(8)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(int64(v)))
+	e.buf.Write(b[:])
+}
+
+// str writes a fixed size character array. The string must leave room
+// for the terminating NUL.
+func (e *legacyEncoder) str(s string, n int, field string) {
+	if e.err == nil && len(s) >= n {
+		e.err = fmt.Errorf("%s: %s: %q is longer than %d characters", e.name, field, s, n-1)
+	}
+	b := make([]byte, n)
+	copy(b, s)
+	e.buf.Write(b)
+}
+
+// skip writes a character array that isn't used, such as padding.
+func (e *legacyEncoder) skip(n int) {
+	e.buf.Write(make([]byte, n))
+}
+
+// bits writes an array of long words where bit n-1 is set for species n.
+func (e *legacyEncoder) bits(set SpeciesSet, field string) {
+	var words [NUM_CONTACT_WORDS]uint32
+	for _, id := range set.IDs() {
+		number, err := strconv.Atoi(id)
+		if err != nil || number < 1 || number > MAX_SPECIES {
+			if e.err == nil {
+				e.err = fmt.Errorf("%s: %s: invalid species id %q", e.name, field, id)
+			}
+			continue
+		}
+		words[(number-1)/32] |= 1 << ((number - 1) % 32)
+	}
+	for _, w := range words {
+		e.long(int(int32(w)), field)
+	}
+}
+
+// end adds the padding at the end of a struct.
+func (e *legacyEncoder) end(hasLong bool) {
+	if hasLong && e.longSize == 8 {
+		e.align(8)
+	} else {
+		e.align(4)
+	}
+}
+
+// write saves the encoded data in the directory.
+func (e *legacyEncoder) write(dir string) error {
+	if e.err != nil {
+		return e.err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, e.name), e.buf.Bytes(), 0644)
+}
+
+// ExportLegacy writes the galaxy as the binary files read by the C
+// server: galaxy.dat, stars.dat, planets.dat and one spNN.dat for each
+// species. longSize is the size in bytes of a C long on the machine
+// that will read the files, either 4 or 8.
+//
+// Planets are written in star order, so the planet indexes of stars and
+// named planets are assigned again. Fields that only exist here, such as
+// the ledger, messages and random number streams, are not written.
+func ExportLegacy(g *GalaxyData, dir string, longSize int) error {
+	if longSize != 4 && longSize != 8 {
+		return fmt.Errorf("long size must be 4 or 8, not %d", longSize)
+	}
+
+	// the C server only looks for species files up to num_species
+	num_species := g.NumSpecies
+	for _, sp := range g.Species {
+		if sp.Number > num_species {
+			num_species = sp.Number
+		}
+	}
+
+	e := newLegacyEncoder("galaxy.dat", longSize)
+	e.int(g.DNumSpecies, "d_num_species")
+	e.int(num_species, "num_species")
+	e.int(g.Radius, "radius")
+	e.int(g.TurnNumber, "turn_number")
+	if err := e.write(dir); err != nil {
+		return err
+	}
+
+	stars, planets := newLegacyEncoder("stars.dat", longSize), newLegacyEncoder("planets.dat", longSize)
+	allStars := g.AllStars()
+	stars.int(len(allStars), "num_stars")
+	num_planets := 0
+	for _, star := range allStars {
+		num_planets += len(star.Planets)
+	}
+	planets.int(num_planets, "num_planets")
+	planetIndex, planet_index := make(map[string]int), 0
+	for _, star := range allStars {
+		planetIndex[star.ID] = planet_index
+		exportLegacyStar(stars, star, planet_index)
+		for _, planet := range star.Planets {
+			exportLegacyPlanet(planets, planet)
+		}
+		planet_index += len(star.Planets)
+	}
+	if err := stars.write(dir); err != nil {
+		return err
+	} else if err := planets.write(dir); err != nil {
+		return err
+	}
+
+	for _, sp := range g.sortedSpecies() {
+		e := newLegacyEncoder(fmt.Sprintf("sp%02d.dat", sp.Number), longSize)
+		exportLegacySpecies(e, sp, func(nampla *NamedPlanetData) int {
+			index, ok := planetIndex[XYZToID(nampla.X, nampla.Y, nampla.Z)]
+			if !ok {
+				return nampla.PlanetIndex
+			}
+			return index + nampla.PN - 1
+		})
+		if err := e.write(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportLegacyStar(e *legacyEncoder, star *StarData, planet_index int) {
+	e.char(star.X, "x")
+	e.char(star.Y, "y")
+	e.char(star.Z, "z")
+	e.char(int(star.Type), "type")
+	e.char(int(star.Color), "color")
+	e.char(star.Size, "size")
+	e.char(len(star.Planets), "num_planets")
+	e.bool(star.HomeSystem, "home_system")
+	e.bool(star.WormHere, "worm_here")
+	e.char(star.WormX, "worm_x")
+	e.char(star.WormY, "worm_y")
+	e.char(star.WormZ, "worm_z")
+	e.short(0, "reserved1")
+	e.short(0, "reserved2")
+	e.short(planet_index, "planet_index")
+	e.long(star.Message, "message")
+	e.bits(star.VisitedBy, "visited_by")
+	e.long(0, "reserved3")
+	e.long(0, "reserved4")
+	e.long(0, "reserved5")
+	e.end(true)
+}
+
+func exportLegacyPlanet(e *legacyEncoder, planet *PlanetData) {
+	e.char(planet.TemperatureClass, "temperature_class")
+	e.char(planet.PressureClass, "pressure_class")
+	e.char(int(planet.Special), "special")
+	e.char(0, "reserved1")
+	var gas, gas_percent [4]int
+	n := 0
+	for _, g := range planet.Gases {
+		if g == nil {
+			continue
+		} else if n == len(gas) {
+			if e.err == nil {
+				e.err = fmt.Errorf("%s: planet %s has more than %d gases", e.name, planet.ID, len(gas))
+			}
+			break
+		}
+		gas[n], gas_percent[n] = int(g.Type), g.Percentage
+		n++
+	}
+	for _, v := range gas {
+		e.char(v, "gas")
+	}
+	for _, v := range gas_percent {
+		e.char(v, "gas_percent")
+	}
+	e.short(0, "reserved2")
+	e.short(planet.Diameter, "diameter")
+	e.short(planet.Gravity, "gravity")
+	e.short(planet.MiningDifficulty, "mining_difficulty")
+	e.short(planet.EconEfficiency, "econ_efficiency")
+	e.short(planet.MDIncrease, "md_increase")
+	e.long(planet.Message, "message")
+	e.long(0, "reserved3")
+	e.long(0, "reserved4")
+	e.long(0, "reserved5")
+	e.end(true)
+}
+
+// exportLegacyGases writes a fixed size array of gases, zero filled.
+func exportLegacyGases(e *legacyEncoder, gases []GasType, n int, field string) {
+	if e.err == nil && len(gases) > n {
+		e.err = fmt.Errorf("%s: %s: more than %d gases", e.name, field, n)
+	}
+	for i := 0; i < n; i++ {
+		if i < len(gases) {
+			e.char(int(gases[i]), field)
+		} else {
+			e.char(0, field)
+		}
+	}
+}
+
+// exportLegacySpecies writes the species followed by its named planets
+// and ships. planetIndex returns the index in planets.dat of a named
+// planet.
+func exportLegacySpecies(e *legacyEncoder, sp *SpeciesData, planetIndex func(*NamedPlanetData) int) {
+	e.str(sp.Name, 32, "name")
+	e.str(sp.GovtName, 32, "govt_name")
+	e.str(sp.GovtType, 32, "govt_type")
+	e.char(sp.X, "x")
+	e.char(sp.Y, "y")
+	e.char(sp.Z, "z")
+	e.char(sp.PN, "pn")
+	e.char(int(sp.RequiredGas), "required_gas")
+	e.char(sp.RequiredGasMin, "required_gas_min")
+	e.char(sp.RequiredGasMax, "required_gas_max")
+	e.char(0, "reserved5")
+	exportLegacyGases(e, sp.NeutralGas, 6, "neutral_gas")
+	exportLegacyGases(e, sp.PoisonGas, 6, "poison_gas")
+	e.bool(sp.AutoOrders, "auto_orders")
+	e.char(0, "reserved3")
+	e.short(0, "reserved4")
+	for _, v := range sp.TechLevel {
+		e.short(v, "tech_level")
+	}
+	for _, v := range sp.InitTechLevel {
+		e.short(v, "init_tech_level")
+	}
+	for _, v := range sp.TechKnowledge {
+		e.short(v, "tech_knowledge")
+	}
+	e.int(len(sp.Namplas), "num_namplas")
+	e.int(len(sp.Ships), "num_ships")
+	for _, v := range sp.TechEps {
+		e.long(v, "tech_eps")
+	}
+	e.long(sp.HPOriginalBase, "hp_original_base")
+	e.long(sp.EconUnits, "econ_units")
+	e.long(sp.FleetCost, "fleet_cost")
+	e.long(sp.FleetPercentCost, "fleet_percent_cost")
+	e.bits(sp.Contact, "contact")
+	e.bits(sp.Ally, "ally")
+	e.bits(sp.Enemy, "enemy")
+	e.skip(12) // padding
+	e.end(true)
+
+	namplaIndex := make(map[string]int)
+	for i, nampla := range sp.Namplas {
+		namplaIndex[nampla.Name] = i
+		exportLegacyNampla(e, nampla, planetIndex(nampla))
+	}
+	pointIndex := func(name string) int {
+		index, ok := namplaIndex[name]
+		if !ok {
+			return 0
+		} else if index == 0 {
+			return LEGACY_HOME_PLANET
+		}
+		return index
+	}
+	for _, ship := range sp.Ships {
+		exportLegacyShip(e, ship, pointIndex(ship.LoadingPoint), pointIndex(ship.UnloadingPoint))
+	}
+}
+
+func exportLegacyNampla(e *legacyEncoder, nampla *NamedPlanetData, planet_index int) {
+	e.str(nampla.Name, 32, "name")
+	e.char(nampla.X, "x")
+	e.char(nampla.Y, "y")
+	e.char(nampla.Z, "z")
+	e.char(nampla.PN, "pn")
+	e.char(int(nampla.Status), "status")
+	e.char(0, "reserved1")
+	e.bool(nampla.Hiding, "hiding")
+	e.bool(nampla.Hidden, "hidden")
+	e.short(0, "reserved2")
+	e.short(planet_index, "planet_index")
+	e.short(nampla.SiegeEff, "siege_eff")
+	e.short(nampla.Shipyards, "shipyards")
+	e.int(0, "reserved4")
+	e.int(nampla.IUsNeeded, "IUs_needed")
+	e.int(nampla.AUsNeeded, "AUs_needed")
+	e.int(nampla.AutoIUs, "auto_IUs")
+	e.int(nampla.AutoAUs, "auto_AUs")
+	e.int(0, "reserved5")
+	e.int(nampla.IUsToInstall, "IUs_to_install")
+	e.int(nampla.AUsToInstall, "AUs_to_install")
+	e.long(nampla.MIBase, "mi_base")
+	e.long(nampla.MABase, "ma_base")
+	e.long(nampla.PopUnits, "pop_units")
+	for _, v := range nampla.ItemQuantity {
+		e.long(v, "item_quantity")
+	}
+	e.long(0, "reserved6")
+	e.long(nampla.UseOnAmbush, "use_on_ambush")
+	e.long(nampla.Message, "message")
+	e.long(nampla.Special, "special")
+	e.skip(28) // padding
+	e.end(true)
+}
+
+func exportLegacyShip(e *legacyEncoder, ship *ShipData, loading_point, unloading_point int) {
+	e.str(ship.Name, 32, "name")
+	e.char(ship.X, "x")
+	e.char(ship.Y, "y")
+	e.char(ship.Z, "z")
+	e.char(ship.PN, "pn")
+	e.char(int(ship.Status), "status")
+	e.char(ship.Type, "type")
+	e.char(ship.DestX, "dest_x")
+	e.char(ship.DestY, "dest_y")
+	e.char(ship.DestZ, "dest_z")
+	e.bool(ship.JustJumped, "just_jumped")
+	e.bool(ship.ArrivedViaWormhole, "arrived_via_wormhole")
+	e.char(0, "reserved1")
+	e.short(0, "reserved2")
+	e.short(0, "reserved3")
+	e.short(int(ship.Class), "class")
+	e.short(ship.Tonnage, "tonnage")
+	for _, v := range ship.ItemQuantity {
+		e.short(v, "item_quantity")
+	}
+	e.short(ship.Age, "age")
+	e.short(ship.RemainingCost, "remaining_cost")
+	e.short(0, "reserved4")
+	e.short(loading_point, "loading_point")
+	e.short(unloading_point, "unloading_point")
+	e.long(ship.Special, "special")
+	e.skip(28) // padding
+	e.end(true)
+}
@@ -0,0 +1,231 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// sizes of the C structs when a long is 4 bytes
+const (
+	legacyStarSize    = 52
+	legacyPlanetSize  = 40
+	legacySpeciesSize = 264
+	legacyNamplaSize  = 288
+	legacyShipSize    = 172
+)
+
+// TestLegacySpeciesLayout decodes a species file with two named planets
+// and a ship, and checks that each struct starts where the C layout
+// puts it.
+func TestLegacySpeciesLayout(t *testing.T) {
+	buf := make([]byte, legacySpeciesSize+2*legacyNamplaSize+legacyShipSize)
+	copy(buf, "Species")
+	binary.LittleEndian.PutUint32(buf[156:], 2) // num_namplas
+	binary.LittleEndian.PutUint32(buf[160:], 1) // num_ships
+	copy(buf[legacySpeciesSize:], "Home")
+	copy(buf[legacySpeciesSize+legacyNamplaSize:], "Second")
+	copy(buf[legacySpeciesSize+2*legacyNamplaSize:], "Ship")
+
+	d := newLegacyDecoder("sp01.dat", buf, 4)
+	sp, err := importLegacySpecies(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.off != len(buf) {
+		t.Errorf("decoded %d bytes, want %d", d.off, len(buf))
+	}
+	if sp.Name != "Species" || len(sp.Namplas) != 2 || sp.Namplas[0].Name != "Home" || sp.Namplas[1].Name != "Second" {
+		t.Errorf("species %q, namplas %v", sp.Name, sp.Namplas)
+	}
+	if len(sp.Ships) != 1 || sp.Ships[0].Name != "Ship" {
+		t.Errorf("ships %v", sp.Ships)
+	}
+
+	// one byte short of the last ship
+	if _, err := importLegacySpecies(newLegacyDecoder("sp01.dat", buf[:len(buf)-1], 4)); err == nil {
+		t.Errorf("truncated species: want an error")
+	}
+}
+
+func TestLegacyStructSizes(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		size   int
+		decode func(d *legacyDecoder)
+	}{
+		{"nampla", legacyNamplaSize, func(d *legacyDecoder) { importLegacyNampla(d) }},
+		{"ship", legacyShipSize, func(d *legacyDecoder) { importLegacyShip(d) }},
+	} {
+		d := newLegacyDecoder(tc.name, make([]byte, tc.size), 4)
+		if tc.decode(d); d.err != nil {
+			t.Errorf("%s: %v", tc.name, d.err)
+		} else if d.off != tc.size {
+			t.Errorf("%s: decoded %d bytes, want %d", tc.name, d.off, tc.size)
+		}
+	}
+}
+
+// TestLegacyStarsAndPlanets decodes two stars and two planets and checks
+// that the second of each is read from the right offset.
+func TestLegacyStarsAndPlanets(t *testing.T) {
+	dir := t.TempDir()
+
+	planets := make([]byte, 4+2*legacyPlanetSize)
+	planets[0] = 2
+	planets[4+legacyPlanetSize] = 7 // temperature class of the second planet
+	if err := ioutil.WriteFile(filepath.Join(dir, "planets.dat"), planets, 0644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := importLegacyPlanets(filepath.Join(dir, "planets.dat"), 4)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 || list[1].TemperatureClass != 7 {
+		t.Errorf("planets: got %d, second has temperature class %d", len(list), list[1].TemperatureClass)
+	}
+
+	stars := make([]byte, 4+2*legacyStarSize)
+	stars[0] = 2
+	stars[4+legacyStarSize] = 5   // x of the second star
+	stars[4+legacyStarSize+6] = 2 // num_planets
+	if err := ioutil.WriteFile(filepath.Join(dir, "stars.dat"), stars, 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := &GalaxyData{Stars: make(map[string]*StarData)}
+	g.Translate.XYZToID = make(map[string]string)
+	if err := g.importLegacyStars(filepath.Join(dir, "stars.dat"), 4, list); err != nil {
+		t.Fatal(err)
+	}
+	star := g.GetStarAt(5, 0, 0)
+	if len(g.Stars) != 2 || star == nil || len(star.Planets) != 2 || star.Planets[1].TemperatureClass != 7 {
+		t.Errorf("stars: got %d, second star %+v", len(g.Stars), star)
+	}
+}
+
+// TestLegacyRoundTrip exports a generated galaxy and imports it back,
+// then compares the fields that the C files hold.
+func TestLegacyRoundTrip(t *testing.T) {
+	for _, longSize := range []int{4, 8} {
+		t.Run(fmt.Sprintf("long=%d", longSize), func(t *testing.T) {
+			g := newLegacyTestGalaxy(t)
+			dir := t.TempDir()
+			if err := ExportLegacy(g, dir, longSize); err != nil {
+				t.Fatal(err)
+			}
+			imported, err := ImportLegacy(dir, longSize, g.Seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, got := legacyFields(t, g), legacyFields(t, imported)
+			if len(got) != len(want) {
+				t.Fatalf("got %d stars and species, want %d", len(got), len(want))
+			}
+			for id, w := range want {
+				if got[id] != w {
+					t.Errorf("%s:\n got %s\nwant %s", id, got[id], w)
+				}
+			}
+		})
+	}
+}
+
+// newLegacyTestGalaxy generates a small galaxy with one species that has
+// a home planet, a colony and two ships, one of them loaded with colonists.
+func newLegacyTestGalaxy(t *testing.T) *GalaxyData {
+	setup := &SetupData{}
+	setup.Galaxy.Overrides.UseOverrides = true
+	setup.Galaxy.Overrides.Radius = MIN_RADIUS
+	setup.Galaxy.Overrides.NumberOfStars = MIN_STARS
+	setup.Players = []PlayerData{{Email: "sp01@example.com", SpeciesName: "Alpha"}}
+	g, err := GenerateGalaxy(setup, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var star *StarData
+	for _, s := range g.AllStars() {
+		if len(s.Planets) > 1 {
+			star = s
+			break
+		}
+	}
+	if star == nil {
+		t.Fatal("no star with two planets")
+	}
+	home := &NamedPlanetData{Name: "Home", X: star.X, Y: star.Y, Z: star.Z, PN: 1, Status: HOME_PLANET | POPULATED, MIBase: 320, MABase: 250, PopUnits: HP_AVAILABLE_POP, Shipyards: 1}
+	home.ItemQuantity[CU] = 100
+	colony := &NamedPlanetData{Name: "Colony", X: star.X, Y: star.Y, Z: star.Z, PN: 2, Status: COLONY, IUsToInstall: 5}
+	transport := &ShipData{Name: "Cargo", X: star.X, Y: star.Y, Z: star.Z, PN: 1, Status: IN_ORBIT, Type: FTL, Class: TR, Tonnage: 10, Age: 3, LoadingPoint: "Home", UnloadingPoint: "Colony"}
+	transport.ItemQuantity[CU] = 20
+	base := &ShipData{Name: "Base", X: star.X, Y: star.Y, Z: star.Z, PN: 2, Status: UNDER_CONSTRUCTION, Type: STARBASE, Class: BA, Tonnage: 5, RemainingCost: 300}
+	sp := &SpeciesData{
+		ID: "01", Number: 1, Name: "Alpha", GovtName: "Council", GovtType: "Democracy",
+		HomeNampla: home, X: star.X, Y: star.Y, Z: star.Z, PN: 1,
+		RequiredGas: O2, RequiredGasMin: 10, RequiredGasMax: 40,
+		NeutralGas: []GasType{HE, H2O, N2}, PoisonGas: []GasType{CL2},
+		TechLevel: [6]int{10, 10, 1, 2, 3, 9}, InitTechLevel: [6]int{10, 10, 1, 2, 3, 9},
+		TechKnowledge: [6]int{10, 10, 1, 2, 3, 9}, TechEps: [6]int{0, 5, 0, 0, 0, 7},
+		EconUnits: 1234, Contact: SpeciesSet{}, Ally: SpeciesSet{}, Enemy: SpeciesSet{},
+		Namplas: []*NamedPlanetData{home, colony}, Ships: []*ShipData{transport, base},
+	}
+	g.Species[sp.ID] = sp
+	g.Translate.SpeciesNameToID[sp.Name] = sp.ID
+	star.VisitedBy[sp.ID] = true
+	if err := g.link(); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// legacyFields returns the json of each star and species, keyed by id,
+// with the fields that aren't stored in the C files cleared.
+func legacyFields(t *testing.T, g *GalaxyData) map[string]string {
+	c, err := g.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]string)
+	add := func(id string, v interface{}) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields[id] = string(b)
+	}
+	for id, star := range c.Stars {
+		star.PlanetIndex = 0
+		for _, planet := range star.Planets {
+			planet.ID, planet.Density = "", 0
+		}
+		add("star "+id, star)
+	}
+	for id, sp := range c.Species {
+		sp.HomeNampla, sp.NumNamplas, sp.NumShips = nil, 0, 0
+		for _, nampla := range sp.Namplas {
+			nampla.ID, nampla.PlanetIndex = "", 0
+		}
+		add("species "+id, sp)
+	}
+	return fields
+}
@@ -0,0 +1,130 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DanglingError lists the references in a galaxy that don't point at
+// anything.
+type DanglingError struct {
+	References []string
+}
+
+func (e *DanglingError) Error() string {
+	return fmt.Sprintf("%d dangling references: %s", len(e.References), strings.Join(e.References, "; "))
+}
+
+// link restores the fields that aren't saved in the JSON file: the
+// pointers between species, named planets, planets and stars, the
+// Translate maps and the cached list of stars. Every reference that
+// can't be resolved is collected and returned as a DanglingError.
+func (g *GalaxyData) link() error {
+	var dangling []string
+	danglingf := func(format string, a ...interface{}) {
+		dangling = append(dangling, fmt.Sprintf(format, a...))
+	}
+
+	// stars, in system number order
+	g.Translate.XYZToID = make(map[string]string)
+	g.Translate.IndexToStarID = nil
+	var stars []*StarData
+	for id, star := range g.Stars {
+		if star.ID != id {
+			danglingf("star %s is stored under %s", star.ID, id)
+		}
+		stars = append(stars, star)
+	}
+	sort.Slice(stars, func(i, j int) bool {
+		if stars[i].SystemNumber != stars[j].SystemNumber {
+			return stars[i].SystemNumber < stars[j].SystemNumber
+		}
+		return stars[i].ID < stars[j].ID
+	})
+	for _, star := range stars {
+		g.Translate.XYZToID[XYZToID(star.X, star.Y, star.Z)] = star.ID
+		g.Translate.IndexToStarID = append(g.Translate.IndexToStarID, star.ID)
+	}
+	g.allStars = stars
+
+	// species and the planets they have named
+	g.Translate.SpeciesNameToID = make(map[string]string)
+	for id, species := range g.Species {
+		if species.ID != id {
+			danglingf("species %s is stored under %s", species.ID, id)
+		}
+		g.Translate.SpeciesNameToID[species.Name] = species.ID
+
+		// older files only have the home planet
+		if len(species.Namplas) == 0 && species.HomeNampla != nil {
+			species.Namplas = append(species.Namplas, species.HomeNampla)
+		} else if len(species.Namplas) != 0 {
+			species.HomeNampla = species.Namplas[0]
+		}
+		species.NumNamplas, species.NumShips = len(species.Namplas), len(species.Ships)
+
+		species.HomePlanet = g.GetPlanet(species.X, species.Y, species.Z, species.PN)
+		if species.HomePlanet == nil {
+			danglingf("SP %s: home planet %d %d %d %d does not exist", species.Name, species.X, species.Y, species.Z, species.PN)
+		}
+		if species.HomeNampla == nil {
+			danglingf("SP %s: no named home planet", species.Name)
+		}
+
+		names := make(map[string]bool)
+		for _, nampla := range species.Namplas {
+			names[nampla.Name] = true
+			nampla.Planet = g.GetPlanet(nampla.X, nampla.Y, nampla.Z, nampla.PN)
+			if nampla.Planet == nil {
+				danglingf("SP %s: PL %s is at %d %d %d %d, where there is no planet", species.Name, nampla.Name, nampla.X, nampla.Y, nampla.Z, nampla.PN)
+			}
+		}
+		for _, ship := range species.Ships {
+			if ship.PN != 0 && g.GetPlanet(ship.X, ship.Y, ship.Z, ship.PN) == nil {
+				danglingf("SP %s: %s is at %d %d %d %d, where there is no planet", species.Name, ship.Display(), ship.X, ship.Y, ship.Z, ship.PN)
+			}
+			if ship.LoadingPoint != "" && !names[ship.LoadingPoint] {
+				danglingf("SP %s: %s: loading point PL %s is not a named planet", species.Name, ship.Display(), ship.LoadingPoint)
+			}
+			if ship.UnloadingPoint != "" && !names[ship.UnloadingPoint] {
+				danglingf("SP %s: %s: unloading point PL %s is not a named planet", species.Name, ship.Display(), ship.UnloadingPoint)
+			}
+		}
+	}
+
+	// players
+	g.Translate.EmailToID = make(map[string]string)
+	for id, player := range g.Players {
+		g.Translate.EmailToID[player.EmailAddress] = id
+		if player.Species != "" {
+			if _, ok := g.Translate.SpeciesNameToID[player.Species]; !ok {
+				danglingf("player %s: species %q does not exist", id, player.Species)
+			}
+		}
+	}
+
+	if dangling != nil {
+		sort.Strings(dangling)
+		return &DanglingError{References: dangling}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDanglingReferences checks that GetGalaxy and Clone return the
+// linked galaxy along with the references that couldn't be resolved.
+func TestDanglingReferences(t *testing.T) {
+	g := &GalaxyData{Species: map[string]*SpeciesData{
+		"01": {ID: "01", Number: 1, Name: "Lost", X: 1, Y: 2, Z: 3, PN: 4, RequiredGas: O2},
+	}}
+	want := []string{
+		"SP Lost: home planet 1 2 3 4 does not exist",
+		"SP Lost: no named home planet",
+	}
+
+	name := filepath.Join(t.TempDir(), "galaxy.json")
+	if err := g.Write(name); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := GetGalaxy(name)
+	var dangling *DanglingError
+	if !errors.As(err, &dangling) || !reflect.DeepEqual(dangling.References, want) {
+		t.Errorf("GetGalaxy: got %v, want %v", err, want)
+	} else if loaded == nil || loaded.Translate.SpeciesNameToID["Lost"] != "01" {
+		t.Errorf("GetGalaxy: want the linked galaxy with the error")
+	}
+
+	clone, err := g.Clone()
+	if !errors.As(err, &dangling) || !reflect.DeepEqual(dangling.References, want) {
+		t.Errorf("Clone: got %v, want %v", err, want)
+	} else if clone == nil || clone == g || clone.Species["01"] == g.Species["01"] {
+		t.Errorf("Clone: want a copy of the galaxy with the error")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import "sort"
+
+// SpeciesLocation records that a species has ships or named planets in
+// a star system. It replaces the sp_loc_data of the original.
+type SpeciesLocation struct {
+	Species *SpeciesData
+	X, Y, Z int
+}
+
+// LocationIndex answers questions about which species are present in
+// each star system. Build it with NewLocationIndex and build it again
+// whenever ships or planets change hands or move.
+type LocationIndex struct {
+	Locations []SpeciesLocation         // sorted by species number, then by coordinates
+	bySystem  map[string][]*SpeciesData // species present, by XYZToID, sorted by species number
+}
+
+// NewLocationIndex finds every species' ships and named planets. Ships
+// that are still under construction and disbanded colonies don't count.
+func NewLocationIndex(g *GalaxyData) *LocationIndex {
+	l := &LocationIndex{bySystem: make(map[string][]*SpeciesData)}
+	for _, sp := range g.sortedSpecies() {
+		var here []SpeciesLocation
+		seen := make(map[string]bool)
+		add := func(x, y, z int) {
+			if id := XYZToID(x, y, z); !seen[id] {
+				seen[id] = true
+				here = append(here, SpeciesLocation{Species: sp, X: x, Y: y, Z: z})
+				l.bySystem[id] = append(l.bySystem[id], sp)
+			}
+		}
+		for _, nampla := range sp.Namplas {
+			if nampla.Status&DISBANDED_COLONY == 0 {
+				add(nampla.X, nampla.Y, nampla.Z)
+			}
+		}
+		for _, ship := range sp.Ships {
+			if ship.Status != UNDER_CONSTRUCTION {
+				add(ship.X, ship.Y, ship.Z)
+			}
+		}
+		sort.Slice(here, func(i, j int) bool {
+			a, b := here[i], here[j]
+			if a.X != b.X {
+				return a.X < b.X
+			} else if a.Y != b.Y {
+				return a.Y < b.Y
+			}
+			return a.Z < b.Z
+		})
+		l.Locations = append(l.Locations, here...)
+	}
+	return l
+}
+
+// SpeciesAt returns the species present at a location, sorted by species number.
+func (l *LocationIndex) SpeciesAt(x, y, z int) []*SpeciesData {
+	return l.bySystem[XYZToID(x, y, z)]
+}
+
+// IsPresent returns true if the species is present at a location.
+func (l *LocationIndex) IsPresent(sp *SpeciesData, x, y, z int) bool {
+	for _, other := range l.SpeciesAt(x, y, z) {
+		if other == sp {
+			return true
+		}
+	}
+	return false
+}
+
+// LocationsOf returns the locations where a species is present.
+func (l *LocationIndex) LocationsOf(sp *SpeciesData) []SpeciesLocation {
+	var locations []SpeciesLocation
+	for _, loc := range l.Locations {
+		if loc.Species == sp {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// UpdateContacts sets the Contact flags of species that share a star
+// system. It returns the contacts that were made for the first time,
+// as pairs of species, in the order of the index.
+func (l *LocationIndex) UpdateContacts() [][2]*SpeciesData {
+	var met [][2]*SpeciesData
+	for _, loc := range l.Locations {
+		sp := loc.Species
+		for _, other := range l.SpeciesAt(loc.X, loc.Y, loc.Z) {
+			if sp.Meet(other) {
+				met = append(met, [2]*SpeciesData{sp, other})
+			}
+		}
+	}
+	return met
+}
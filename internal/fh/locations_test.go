@@ -0,0 +1,89 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newLocationsGalaxy returns a galaxy where two species share the
+// system at 1 2 3. Disbanded colonies and ships under construction
+// don't count as being present.
+func newLocationsGalaxy() (*GalaxyData, *SpeciesData, *SpeciesData) {
+	sp1 := &SpeciesData{ID: "01", Number: 1, Name: "One"}
+	sp1.Namplas = []*NamedPlanetData{
+		{Name: "Home", X: 1, Y: 2, Z: 3, PN: 1, Status: HOME_PLANET},
+		{Name: "Colony", X: 1, Y: 2, Z: 3, PN: 2, Status: COLONY},
+		{Name: "Gone", X: 5, Y: 5, Z: 5, PN: 1, Status: COLONY | DISBANDED_COLONY},
+	}
+	sp1.Ships = []*ShipData{
+		{Name: "Scout", X: 7, Y: 7, Z: 7, Status: IN_DEEP_SPACE},
+		{Name: "Hull", X: 9, Y: 9, Z: 9, PN: 1, Status: UNDER_CONSTRUCTION},
+	}
+	sp2 := &SpeciesData{ID: "02", Number: 2, Name: "Two"}
+	sp2.Namplas = []*NamedPlanetData{{Name: "Far", X: 8, Y: 8, Z: 8, PN: 1, Status: HOME_PLANET}}
+	sp2.Ships = []*ShipData{{Name: "Visitor", X: 1, Y: 2, Z: 3, PN: 1, Status: IN_ORBIT}}
+	g := &GalaxyData{Species: map[string]*SpeciesData{sp1.ID: sp1, sp2.ID: sp2}}
+	return g, sp1, sp2
+}
+
+func TestLocationIndex(t *testing.T) {
+	g, sp1, sp2 := newLocationsGalaxy()
+	l := NewLocationIndex(g)
+
+	var got []string
+	for _, loc := range l.Locations {
+		got = append(got, fmt.Sprintf("%s %d %d %d", loc.Species.ID, loc.X, loc.Y, loc.Z))
+	}
+	if want := "[01 1 2 3 01 7 7 7 02 1 2 3 02 8 8 8]"; fmt.Sprint(got) != want {
+		t.Errorf("locations: got %v, want %s", got, want)
+	}
+
+	if species := l.SpeciesAt(1, 2, 3); len(species) != 2 || species[0] != sp1 || species[1] != sp2 {
+		t.Errorf("species at 1 2 3: got %d, want One and Two", len(species))
+	}
+	for _, xyz := range [][3]int{{5, 5, 5}, {9, 9, 9}, {0, 0, 0}} {
+		if species := l.SpeciesAt(xyz[0], xyz[1], xyz[2]); len(species) != 0 {
+			t.Errorf("species at %v: got %d, want none", xyz, len(species))
+		}
+	}
+	if !l.IsPresent(sp2, 8, 8, 8) || l.IsPresent(sp1, 8, 8, 8) {
+		t.Errorf("present at 8 8 8: want Two and not One")
+	}
+	if locations := l.LocationsOf(sp2); len(locations) != 2 || locations[0].X != 1 || locations[1].X != 8 {
+		t.Errorf("locations of Two: got %v", locations)
+	}
+}
+
+func TestUpdateContacts(t *testing.T) {
+	g, sp1, sp2 := newLocationsGalaxy()
+	met := NewLocationIndex(g).UpdateContacts()
+	if len(met) != 2 || met[0] != [2]*SpeciesData{sp1, sp2} || met[1] != [2]*SpeciesData{sp2, sp1} {
+		t.Errorf("first contact: got %v, want One met Two and Two met One", met)
+	}
+	if !sp1.Contact.Has(sp2.ID) || !sp2.Contact.Has(sp1.ID) {
+		t.Errorf("contact flags: got %v and %v", sp1.Contact, sp2.Contact)
+	}
+
+	// species only meet once
+	if met := NewLocationIndex(g).UpdateContacts(); len(met) != 0 {
+		t.Errorf("second contact: got %v, want none", met)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+// Message is a free-text message sent from one species to another with
+// the MESSAGE order. Every message is kept in the galaxy's archive; the
+// MESSAGE_TO_SPECIES transaction that delivers it refers to it by ID.
+type Message struct {
+	ID   int      `json:"id"` // one-based index into the archive
+	Turn int      `json:"turn"`
+	From string   `json:"from"` // species id of the sender
+	To   string   `json:"to"`   // species id of the recipient
+	Text []string `json:"text"`
+}
+
+// AddMessage adds a message to the archive and returns its ID.
+func (g *GalaxyData) AddMessage(m *Message) int {
+	m.ID = len(g.Messages) + 1
+	g.Messages = append(g.Messages, m)
+	return m.ID
+}
+
+// GetMessage returns the archived message with the given ID, or nil.
+func (g *GalaxyData) GetMessage(id int) *Message {
+	if id < 1 || id > len(g.Messages) {
+		return nil
+	}
+	return g.Messages[id-1]
+}
+
+// MessagesFor returns the archived messages sent to or from a species,
+// oldest first.
+func (g *GalaxyData) MessagesFor(speciesID string) []*Message {
+	var list []*Message
+	for _, m := range g.Messages {
+		if m.From == speciesID || m.To == speciesID {
+			list = append(list, m)
+		}
+	}
+	return list
+}
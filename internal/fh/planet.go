@@ -64,6 +64,7 @@ type NamedPlanetData struct {
 	Message      int            /* Message associated with this planet, if any. */
 	Special      int            /* Different for each application. */
 	ItemQuantity [MAX_ITEMS]int /* Quantity of each item available. */
+	Planet       *PlanetData    `json:"-"` // the planet that was named, set when the galaxy is loaded
 }
 
 // Values for the planets of Earth's solar system will be used as starting values.
@@ -86,7 +87,7 @@ var earth = []struct{ diameter, temperatureClass int }{
 
 // GenerateEarthLikePlanet will try to random generate a set of planets
 // that contains one Earth-like planet. If it can't, it will return nil.
-func GenerateEarthLikePlanet(starId string, num_planets int) []*PlanetData {
+func GenerateEarthLikePlanet(r *RNG, starId string, num_planets int) []*PlanetData {
 	// set flag to indicate this star system requires an earth-like planet.
 	// We will reset it after we have created one.
 	make_earth := true
@@ -106,13 +107,13 @@ func GenerateEarthLikePlanet(starId string, num_planets int) []*PlanetData {
 		} else {
 			startOffset = (9 * planet_number) / num_planets
 		}
-		planet.Diameter = planet.GenerateDiameter(earth[startOffset].diameter)
+		planet.Diameter = planet.GenerateDiameter(r, earth[startOffset].diameter)
 		planet.TemperatureClass = earth[startOffset].temperatureClass
 
 		/* If diameter is greater than 40,000 km, assume the planet is a gas giant. */
 		gas_giant := (planet.Diameter > 40)
 
-		planet.Density = planet.GenerateDensity(gas_giant)
+		planet.Density = planet.GenerateDensity(r, gas_giant)
 
 		// Gravitational acceleration is proportional to the mass divided by the radius-squared.
 		// The radius is proportional to the diameter, and the mass is proportional to the density times the radius-cubed.
@@ -121,10 +122,10 @@ func GenerateEarthLikePlanet(starId string, num_planets int) []*PlanetData {
 		// The factor 72 ensures that "g" will be 100 for Earth (density=550 and diameter=13).
 		planet.Gravity = (planet.Density * planet.Diameter) / 72
 
-		planet.TemperatureClass = planet.GenerateTemperatureClass(num_planets, planet_number, gas_giant, earth[startOffset].temperatureClass)
+		planet.TemperatureClass = planet.GenerateTemperatureClass(r, num_planets, planet_number, gas_giant, earth[startOffset].temperatureClass)
 		/* Make sure that planets farther from the sun are not warmer than planets closer to the sun. */
 		if planet_number > 1 && planets[planet_number-1].TemperatureClass < planet.TemperatureClass {
-			planet.TemperatureClass = planets[planet_number-1].TemperatureClass - (rnd(3) - 1)
+			planet.TemperatureClass = planets[planet_number-1].TemperatureClass - (r.Roll(3) - 1)
 			if planet.TemperatureClass < 1 {
 				planet.TemperatureClass = 1
 			}
@@ -135,30 +136,30 @@ func GenerateEarthLikePlanet(starId string, num_planets int) []*PlanetData {
 		if make_earth && homePlanet == nil && planet.TemperatureClass <= 11 {
 			homePlanet, make_earth = planet, false /* Once only. */
 
-			planet.Diameter = 11 + rnd(3)
-			planet.Gravity = 93 + rnd(11) + rnd(11) + rnd(5)
-			planet.TemperatureClass = 9 + rnd(3)
-			planet.PressureClass = 8 + rnd(3)
-			planet.MiningDifficulty = 208 + rnd(11) + rnd(11)
+			planet.Diameter = 11 + r.Roll(3)
+			planet.Gravity = 93 + r.Roll(11) + r.Roll(11) + r.Roll(5)
+			planet.TemperatureClass = 9 + r.Roll(3)
+			planet.PressureClass = 8 + r.Roll(3)
+			planet.MiningDifficulty = 208 + r.Roll(11) + r.Roll(11)
 			planet.Special = IDEAL_HOME_PLANET /* Maybe ideal home planet. */
 
 			pctRemaining := 100
-			if rnd(3) == 1 {
+			if r.Roll(3) == 1 {
 				/* Give it a shot of ammonia. */
-				gas := &GasData{NH3, rnd(30)}
+				gas := &GasData{NH3, r.Roll(30)}
 				planet.Gases = append(planet.Gases, gas)
 				pctRemaining -= gas.Percentage
 			}
 
-			if rnd(3) == 1 {
+			if r.Roll(3) == 1 {
 				/* Give it a shot of carbon dioxide. */
-				gas := &GasData{CO2, rnd(30)}
+				gas := &GasData{CO2, r.Roll(30)}
 				planet.Gases = append(planet.Gases, gas)
 				pctRemaining -= gas.Percentage
 			}
 
 			/* Now do oxygen. */
-			gas := &GasData{O2, rnd(20) + 10}
+			gas := &GasData{O2, r.Roll(20) + 10}
 			planet.Gases = append(planet.Gases, gas)
 			pctRemaining -= gas.Percentage
 
@@ -170,15 +171,15 @@ func GenerateEarthLikePlanet(starId string, num_planets int) []*PlanetData {
 		}
 
 		/* Pressure class depends primarily on gravity. Calculate an approximate value and randomize it. */
-		planet.PressureClass = planet.GeneratePressureClass(planet.Gravity, planet.TemperatureClass, gas_giant)
+		planet.PressureClass = planet.GeneratePressureClass(r, planet.Gravity, planet.TemperatureClass, gas_giant)
 
 		/* Generate gases, if any, in the atmosphere. */
-		for _, gas := range planet.GenerateGases(planet.PressureClass, planet.TemperatureClass) {
+		for _, gas := range planet.GenerateGases(r, planet.PressureClass, planet.TemperatureClass) {
 			planet.Gases = append(planet.Gases, gas)
 		}
 
 		// Get mining difficulty.
-		planet.MiningDifficulty = planet.GenerateMiningDifficulty(planet.Diameter, true)
+		planet.MiningDifficulty = planet.GenerateMiningDifficulty(r, planet.Diameter, true)
 	}
 
 	if homePlanet == nil {
@@ -200,7 +201,7 @@ func GenerateEarthLikePlanet(starId string, num_planets int) []*PlanetData {
 	return planets
 }
 
-func GeneratePlanet(starId string, num_planets int) ([]*PlanetData, error) {
+func GeneratePlanet(r *RNG, starId string, num_planets int) ([]*PlanetData, error) {
 	var planets []*PlanetData
 
 	/* Main loop. Generate one planet at a time. */
@@ -215,13 +216,13 @@ func GeneratePlanet(starId string, num_planets int) ([]*PlanetData, error) {
 		} else {
 			startOffset = (9 * planet_number) / num_planets
 		}
-		planet.Diameter = planet.GenerateDiameter(earth[startOffset].diameter)
+		planet.Diameter = planet.GenerateDiameter(r, earth[startOffset].diameter)
 		planet.TemperatureClass = earth[startOffset].temperatureClass
 
 		/* If diameter is greater than 40,000 km, assume the planet is a gas giant. */
 		gas_giant := (planet.Diameter > 40)
 
-		planet.Density = planet.GenerateDensity(gas_giant)
+		planet.Density = planet.GenerateDensity(r, gas_giant)
 
 		// Gravitational acceleration is proportional to the mass divided by the radius-squared.
 		// The radius is proportional to the diameter, and the mass is proportional to the density times the radius-cubed.
@@ -230,25 +231,25 @@ func GeneratePlanet(starId string, num_planets int) ([]*PlanetData, error) {
 		// The factor 72 ensures that "g" will be 100 for Earth (density=550 and diameter=13).
 		planet.Gravity = (planet.Density * planet.Diameter) / 72
 
-		planet.TemperatureClass = planet.GenerateTemperatureClass(num_planets, planet_number, gas_giant, earth[startOffset].temperatureClass)
+		planet.TemperatureClass = planet.GenerateTemperatureClass(r, num_planets, planet_number, gas_giant, earth[startOffset].temperatureClass)
 		/* Make sure that planets farther from the sun are not warmer than planets closer to the sun. */
 		if planet_number > 1 && planets[planet_number-1].TemperatureClass < planet.TemperatureClass {
-			planet.TemperatureClass = planets[planet_number-1].TemperatureClass - (rnd(3) - 1)
+			planet.TemperatureClass = planets[planet_number-1].TemperatureClass - (r.Roll(3) - 1)
 			if planet.TemperatureClass < 1 {
 				planet.TemperatureClass = 1
 			}
 		}
 
 		/* Pressure class depends primarily on gravity. Calculate an approximate value and randomize it. */
-		planet.PressureClass = planet.GeneratePressureClass(planet.Gravity, planet.TemperatureClass, gas_giant)
+		planet.PressureClass = planet.GeneratePressureClass(r, planet.Gravity, planet.TemperatureClass, gas_giant)
 
 		/* Generate gases, if any, in the atmosphere. */
-		for _, gas := range planet.GenerateGases(planet.PressureClass, planet.TemperatureClass) {
+		for _, gas := range planet.GenerateGases(r, planet.PressureClass, planet.TemperatureClass) {
 			planet.Gases = append(planet.Gases, gas)
 		}
 
 		// Get mining difficulty.
-		planet.MiningDifficulty = planet.GenerateMiningDifficulty(planet.Diameter, false)
+		planet.MiningDifficulty = planet.GenerateMiningDifficulty(r, planet.Diameter, false)
 	}
 
 	return planets, nil
@@ -281,7 +282,7 @@ func (p *PlanetData) Clone() *PlanetData {
 // Density will depend on whether or not the planet is a gas giant.
 // Again ignoring Pluto, densities range from 0.7 to 1.6 times the density of water for the gas giants, and from 3.9 to 5.5 for the others.
 // We will expand this range slightly and use 100 times the actual density so that we can use integer arithmetic.
-func (p *PlanetData) GenerateDensity(gasGiant bool) int {
+func (p *PlanetData) GenerateDensity(r *RNG, gasGiant bool) int {
 	var base, sigma int
 	if gasGiant {
 		/* Final values from 60 thru 170. */
@@ -290,27 +291,27 @@ func (p *PlanetData) GenerateDensity(gasGiant bool) int {
 		/* Final values from 370 thru 570. */
 		base, sigma = 368, 101
 	}
-	return base + rnd(sigma) + rnd(sigma)
+	return base + r.Roll(sigma) + r.Roll(sigma)
 }
 
 // GenerateDiameter
-func (p *PlanetData) GenerateDiameter(baseDiameter int) int {
+func (p *PlanetData) GenerateDiameter(r *RNG, baseDiameter int) int {
 	diameter, die_size := baseDiameter, baseDiameter/4
 	if die_size < 2 {
 		die_size = 2
 	}
 	for i := 1; i <= 4; i++ {
-		if rnd(100) > 50 {
-			diameter += rnd(die_size)
+		if r.Roll(100) > 50 {
+			diameter += r.Roll(die_size)
 		} else {
-			diameter -= rnd(die_size)
+			diameter -= r.Roll(die_size)
 		}
 	}
 
 	// Minimum allowable diameter is 3,000 km.
 	// Note that the maximum diameter we can generate is 283,000 km.
 	for diameter < 3 {
-		diameter += rnd(4)
+		diameter += r.Roll(4)
 	}
 
 	return diameter
@@ -318,7 +319,7 @@ func (p *PlanetData) GenerateDiameter(baseDiameter int) int {
 
 // GenerateGases
 /* Generate gases, if any, in the atmosphere. */
-func (p *PlanetData) GenerateGases(pressureClass, temperatureClass int) []*GasData {
+func (p *PlanetData) GenerateGases(r *RNG, pressureClass, temperatureClass int) []*GasData {
 	if pressureClass == 0 {
 		// no atmosphere, no gases
 		return nil
@@ -352,7 +353,7 @@ func (p *PlanetData) GenerateGases(pressureClass, temperatureClass int) []*GasDa
 	}
 
 	/* The following algorithm is something I tweaked until it worked well. */
-	num_gases_wanted := (rnd(4) + rnd(4)) / 2
+	num_gases_wanted := (r.Roll(4) + r.Roll(4)) / 2
 	for len(gases) == 0 {
 		for i := firstGas; i <= firstGas+4 && len(gases) < num_gases_wanted; i++ {
 			if i == HE && temperatureClass > 5 {
@@ -361,7 +362,7 @@ func (p *PlanetData) GenerateGases(pressureClass, temperatureClass int) []*GasDa
 			}
 			// skip to the next gas about one-third of the time
 			// (unless we're on Helium, then it's two-thirds of the time)
-			switch rnd(3) {
+			switch r.Roll(3) {
 			case 2:
 				if i == HE {
 					/* Don't want too many Helium planets. */
@@ -375,12 +376,12 @@ func (p *PlanetData) GenerateGases(pressureClass, temperatureClass int) []*GasDa
 			switch i {
 			case HE:
 				// Helium is self-limiting
-				gas.Percentage = rnd(20)
+				gas.Percentage = r.Roll(20)
 			case O2:
 				// Oxygen is self-limiting
-				gas.Percentage = rnd(50)
+				gas.Percentage = r.Roll(50)
 			default:
-				gas.Percentage = rnd(100)
+				gas.Percentage = r.Roll(100)
 			}
 			gases = append(gases, gas)
 		}
@@ -409,19 +410,19 @@ func (p *PlanetData) GenerateGases(pressureClass, temperatureClass int) []*GasDa
 // Earth-like values will range between 0.30 and 10.00.
 // Non earth-like values will range between 0.80 and 10.00.
 // Again, the actual value will be multiplied by 100 to allow use of integer arithmetic.
-func (p *PlanetData) GenerateMiningDifficulty(diameter int, earthLike bool) int {
+func (p *PlanetData) GenerateMiningDifficulty(r *RNG, diameter int, earthLike bool) int {
 	mining_dif := 0
 	for mining_dif < 40 || mining_dif > 500 {
-		mining_dif = (rnd(3)+rnd(3)+rnd(3)-rnd(4))*rnd(diameter) + rnd(30) + rnd(30)
+		mining_dif = (r.Roll(3)+r.Roll(3)+r.Roll(3)-r.Roll(4))*r.Roll(diameter) + r.Roll(30) + r.Roll(30)
 	}
 
 	if earthLike {
 		for mining_dif < 30 || mining_dif > 1000 {
-			mining_dif = (rnd(3)+rnd(3)+rnd(3)-rnd(4))*rnd(diameter) + rnd(20) + rnd(20)
+			mining_dif = (r.Roll(3)+r.Roll(3)+r.Roll(3)-r.Roll(4))*r.Roll(diameter) + r.Roll(20) + r.Roll(20)
 		}
 	} else {
 		for mining_dif < 40 || mining_dif > 500 {
-			mining_dif = (rnd(3)+rnd(3)+rnd(3)-rnd(4))*rnd(diameter) + rnd(30) + rnd(30)
+			mining_dif = (r.Roll(3)+r.Roll(3)+r.Roll(3)-r.Roll(4))*r.Roll(diameter) + r.Roll(30) + r.Roll(30)
 		}
 		mining_dif = (mining_dif * 11) / 5 /* Fudge factor. */
 	}
@@ -430,7 +431,7 @@ func (p *PlanetData) GenerateMiningDifficulty(diameter int, earthLike bool) int
 }
 
 // GeneratePressureClass
-func (p *PlanetData) GeneratePressureClass(gravity, temperatureClass int, gasGiant bool) int {
+func (p *PlanetData) GeneratePressureClass(r *RNG, gravity, temperatureClass int, gasGiant bool) int {
 	if gravity < 10 {
 		// gravity is too low to retain an atmosphere
 		return 0
@@ -444,27 +445,27 @@ func (p *PlanetData) GeneratePressureClass(gravity, temperatureClass int, gasGia
 	if die_size < 2 {
 		die_size = 2
 	}
-	for i, nRolls := 1, rnd(3)+rnd(3)+rnd(3); i <= nRolls; i++ {
-		if rnd(100) > 50 {
-			pressureClass += rnd(die_size)
+	for i, nRolls := 1, r.Roll(3)+r.Roll(3)+r.Roll(3); i <= nRolls; i++ {
+		if r.Roll(100) > 50 {
+			pressureClass += r.Roll(die_size)
 		} else {
-			pressureClass -= rnd(die_size)
+			pressureClass -= r.Roll(die_size)
 		}
 	}
 
 	if gasGiant {
 		for pressureClass < 11 {
-			pressureClass += rnd(3)
+			pressureClass += r.Roll(3)
 		}
 		for pressureClass > 29 {
-			pressureClass -= rnd(3)
+			pressureClass -= r.Roll(3)
 		}
 	} else {
 		for pressureClass < 0 {
-			pressureClass += rnd(3)
+			pressureClass += r.Roll(3)
 		}
 		for pressureClass > 12 {
-			pressureClass -= rnd(3)
+			pressureClass -= r.Roll(3)
 		}
 	}
 
@@ -472,34 +473,34 @@ func (p *PlanetData) GeneratePressureClass(gravity, temperatureClass int, gasGia
 }
 
 // GenerateTemperatureClass
-func (p *PlanetData) GenerateTemperatureClass(numPlanets, orbit int, gasGiant bool, baseTemperatureClass int) int {
+func (p *PlanetData) GenerateTemperatureClass(r *RNG, numPlanets, orbit int, gasGiant bool, baseTemperatureClass int) int {
 	/* Randomize the temperature class obtained earlier. */
 	temperatureClass, die_size := baseTemperatureClass, baseTemperatureClass/4
 	if die_size < 2 {
 		die_size = 2
 	}
-	n_rolls := rnd(3) + rnd(3) + rnd(3)
+	n_rolls := r.Roll(3) + r.Roll(3) + r.Roll(3)
 	for i := 1; i <= n_rolls; i++ {
-		if rnd(100) > 50 {
-			temperatureClass += rnd(die_size)
+		if r.Roll(100) > 50 {
+			temperatureClass += r.Roll(die_size)
 		} else {
-			temperatureClass -= rnd(die_size)
+			temperatureClass -= r.Roll(die_size)
 		}
 	}
 
 	if gasGiant {
 		for temperatureClass < 3 {
-			temperatureClass += rnd(2)
+			temperatureClass += r.Roll(2)
 		}
 		for temperatureClass > 7 {
-			temperatureClass -= rnd(2)
+			temperatureClass -= r.Roll(2)
 		}
 	} else {
 		for temperatureClass < 1 {
-			temperatureClass += rnd(3)
+			temperatureClass += r.Roll(3)
 		}
 		for temperatureClass > 30 {
-			temperatureClass -= rnd(3)
+			temperatureClass -= r.Roll(3)
 		}
 	}
 
@@ -507,7 +508,7 @@ func (p *PlanetData) GenerateTemperatureClass(numPlanets, orbit int, gasGiant bo
 	// Warm them up a little.
 	if numPlanets < 4 && orbit < 3 {
 		for temperatureClass < 12 {
-			temperatureClass += rnd(4)
+			temperatureClass += r.Roll(4)
 		}
 	}
 
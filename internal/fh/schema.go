@@ -0,0 +1,223 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// SCHEMA_VERSION is the version of the galaxy file written by Write.
+// Bump it whenever a change to GalaxyData would stop an older file from
+// loading correctly, and add the migration that upgrades the older file.
+const SCHEMA_VERSION = 1
+
+// migration upgrades a galaxy document from one schema version to the
+// next. It works on the decoded json, before the document is loaded
+// into GalaxyData, so it can still see fields that have been removed.
+type migration struct {
+	description string
+	upgrade     func(doc map[string]interface{}) error
+}
+
+// migrations[n] upgrades a document from version n to version n+1.
+// Files written before the version was added are version 0.
+var migrations = []migration{
+	{"store enums as names, species sets as objects and every named planet in namplas", migrateToV1},
+}
+
+// upgradeGalaxy returns the json for a galaxy document upgraded to the
+// current schema version, along with the version it started at.
+func upgradeGalaxy(data []byte) ([]byte, int, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, 0, err
+	}
+	version := header.SchemaVersion
+	if version == SCHEMA_VERSION {
+		return data, version, nil
+	} else if version < 0 || version > SCHEMA_VERSION {
+		return nil, version, fmt.Errorf("galaxy has schema version %d, this program supports up to %d", version, SCHEMA_VERSION)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, version, err
+	}
+	for v := version; v < SCHEMA_VERSION; v++ {
+		if err := migrations[v].upgrade(doc); err != nil {
+			return nil, version, fmt.Errorf("schema version %d to %d: %w", v, v+1, err)
+		}
+		doc["schema_version"] = v + 1
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, err
+	}
+	return data, version, nil
+}
+
+// MigrateGalaxy upgrades a galaxy file to the current schema version.
+// The original file is kept as NAME.vN.bak, where N is its version.
+// Returns the version the file started at.
+func MigrateGalaxy(name string) (int, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	upgraded, version, err := upgradeGalaxy(data)
+	if err != nil {
+		return version, err
+	} else if version == SCHEMA_VERSION {
+		return version, nil
+	}
+	var galaxy GalaxyData
+	if err := json.Unmarshal(upgraded, &galaxy); err != nil {
+		return version, err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", name, version)
+	if err := ioutil.WriteFile(backup, data, 0644); err != nil {
+		return version, err
+	}
+	return version, galaxy.Write(name)
+}
+
+// MigrationDescription returns the description of the migration that
+// upgrades a document from the given version.
+func MigrationDescription(version int) string {
+	if version < 0 || version >= len(migrations) {
+		return ""
+	}
+	return migrations[version].description
+}
+
+// objects returns the json objects in an array, or the values of an
+// object keyed by id.
+func objects(v interface{}) []map[string]interface{} {
+	var list []map[string]interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			if m, ok := e.(map[string]interface{}); ok {
+				list = append(list, m)
+			}
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			if m, ok := e.(map[string]interface{}); ok {
+				list = append(list, m)
+			}
+		}
+	}
+	return list
+}
+
+// recode loads a field into a value of its current type and stores it
+// back, so that the type's UnmarshalJSON converts older encodings.
+func recode(m map[string]interface{}, key string, v interface{}) error {
+	raw, ok := m[key]
+	if !ok || raw == nil {
+		return nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	} else if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if b, err = json.Marshal(v); err != nil {
+		return err
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return err
+	}
+	m[key] = out
+	return nil
+}
+
+// migrateToV1 replaces the enum numbers in the first files with names,
+// the species flag arrays with objects keyed by species id, and the lone
+// home planet with a list of named planets.
+func migrateToV1(doc map[string]interface{}) error {
+	recodePlanet := func(planet map[string]interface{}) error {
+		if err := recode(planet, "Special", new(PlanetSpecialType)); err != nil {
+			return err
+		}
+		for _, gas := range objects(planet["Gases"]) {
+			if err := recode(gas, "Type", new(GasType)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, star := range objects(doc["Stars"]) {
+		if err := recode(star, "Type", new(StarType)); err != nil {
+			return err
+		} else if err := recode(star, "Color", new(StarColor)); err != nil {
+			return err
+		}
+		for _, planet := range objects(star["Planets"]) {
+			if err := recodePlanet(planet); err != nil {
+				return err
+			}
+		}
+	}
+	if templates, ok := doc["Templates"].(map[string]interface{}); ok {
+		if homes, ok := templates["Homes"].([]interface{}); ok {
+			for _, home := range homes {
+				for _, planet := range objects(home) {
+					if err := recodePlanet(planet); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	for _, sp := range objects(doc["Species"]) {
+		if err := recode(sp, "RequiredGas", new(GasType)); err != nil {
+			return err
+		} else if err := recode(sp, "NeutralGas", new([]GasType)); err != nil {
+			return err
+		} else if err := recode(sp, "PoisonGas", new([]GasType)); err != nil {
+			return err
+		}
+		for _, key := range []string{"Contact", "Ally", "Enemy"} {
+			if err := recode(sp, key, new(SpeciesSet)); err != nil {
+				return err
+			}
+		}
+		if namplas, _ := sp["namplas"].([]interface{}); len(namplas) == 0 && sp["HomeNampla"] != nil {
+			sp["namplas"] = []interface{}{sp["HomeNampla"]}
+		}
+		for _, ship := range objects(sp["ships"]) {
+			if err := recode(ship, "Status", new(ShipStatus)); err != nil {
+				return err
+			} else if err := recode(ship, "Class", new(ShipClass)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// baselineGalaxy was written by the create galaxy command before schema
+// versions existed.
+const baselineGalaxy = "testdata/galaxy.v0.json"
+
+func TestMigrateBaselineGalaxy(t *testing.T) {
+	loaded, err := GetGalaxy(baselineGalaxy)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want, err := loaded.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(baselineGalaxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "galaxy.json")
+	if err := ioutil.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	version, err := MigrateGalaxy(name)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	} else if version != 0 {
+		t.Errorf("migrate: started at version %d, want 0", version)
+	}
+	if backup, err := ioutil.ReadFile(name + ".v0.bak"); err != nil {
+		t.Errorf("backup: %v", err)
+	} else if string(backup) != string(data) {
+		t.Errorf("backup: does not match the original file")
+	}
+
+	migrated, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(migrated, &header); err != nil {
+		t.Fatal(err)
+	} else if header.SchemaVersion != SCHEMA_VERSION {
+		t.Errorf("schema_version: got %d, want %d", header.SchemaVersion, SCHEMA_VERSION)
+	}
+	reloaded, err := GetGalaxy(name)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got, err := reloaded.Hash(); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		diff, _ := FirstDifference(loaded, reloaded)
+		t.Errorf("hash: migrated file differs from the upgraded load: %s", diff)
+	}
+
+	// a current file is left alone
+	if version, err := MigrateGalaxy(name); err != nil || version != SCHEMA_VERSION {
+		t.Errorf("migrate again: got %d, %v, want %d, nil", version, err, SCHEMA_VERSION)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.v%d.bak", name, SCHEMA_VERSION)); !os.IsNotExist(err) {
+		t.Errorf("migrate again: wrote a backup")
+	}
+}
@@ -33,9 +33,10 @@ type SetupData struct {
 			Radius        int  `json:"radius"`
 			NumberOfStars int  `json:"number_of_stars"`
 		}
-		LowDensity            bool `json:"low_density"`
-		ForbidNearbyWormholes bool `json:"forbid_nearby_wormholes"`
-		MinimumDistance       int  `json:"minimum_distance"`
+		LowDensity            bool   `json:"low_density"`
+		ForbidNearbyWormholes bool   `json:"forbid_nearby_wormholes"`
+		MinimumDistance       int    `json:"minimum_distance"`
+		Seed                  uint64 `json:"seed,omitempty"` // seed for the random number generator, zero to use the time
 	} `json:"galaxy"`
 	Players []PlayerData `json:"players"`
 }
@@ -0,0 +1,226 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ShipData is a ship owned by a species.
+type ShipData struct {
+	Name               string         /* Name of ship, without the class abbreviation. */
+	X, Y, Z, PN        int            /* Current coordinates. */
+	Status             ShipStatus     /* Current status of ship. */
+	Type               int            /* FTL, SUB_LIGHT or STARBASE. */
+	Class              ShipClass      /* Ship class. */
+	Tonnage            int            /* Ship tonnage divided by 10,000. */
+	Age                int            /* Ship age. */
+	DestX, DestY       int            /* Destination if ship was forced to jump from combat. */
+	DestZ              int            /* Ditto. */
+	JustJumped         bool           /* Set if ship jumped this turn. */
+	ArrivedViaWormhole bool           /* Ship arrived via wormhole in the PREVIOUS turn. */
+	ItemQuantity       [MAX_ITEMS]int /* Quantity of each item carried. */
+	RemainingCost      int            /* The cost needed to complete the ship if still under construction. */
+	LoadingPoint       string         /* Name of the planet where the ship was last loaded with CUs. Empty if none. */
+	UnloadingPoint     string         /* Name of the planet where the ship should unload its CUs. Empty if none. */
+	Special            int            /* Different for each application. */
+}
+
+// ShipStatus is the current status of a ship.
+type ShipStatus int
+
+func (t ShipStatus) String() string {
+	switch t {
+	case UNDER_CONSTRUCTION:
+		return "under-construction"
+	case ON_SURFACE:
+		return "on-surface"
+	case IN_ORBIT:
+		return "in-orbit"
+	case IN_DEEP_SPACE:
+		return "in-deep-space"
+	case JUMPED_IN_COMBAT:
+		return "jumped-in-combat"
+	case FORCED_JUMP:
+		return "forced-jump"
+	}
+	return "unknown"
+}
+
+// Description returns the status as it is shown in reports.
+func (t ShipStatus) Description() string {
+	switch t {
+	case UNDER_CONSTRUCTION:
+		return "Under Construction"
+	case ON_SURFACE:
+		return "On Surface"
+	case IN_ORBIT:
+		return "In Orbit"
+	case IN_DEEP_SPACE:
+		return "In Deep Space"
+	case JUMPED_IN_COMBAT:
+		return "Jumped in Combat"
+	case FORCED_JUMP:
+		return "Forced Jump"
+	}
+	return "Unknown"
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (t ShipStatus) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(t.String())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmarshals a quoted json string to the enum value.
+// Older files stored the status as a number, so numbers are accepted too.
+func (t *ShipStatus) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		if n < UNDER_CONSTRUCTION || n > FORCED_JUMP {
+			return fmt.Errorf("invalid ShipStatus %d", n)
+		}
+		*t = ShipStatus(n)
+		return nil
+	}
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	switch string(b) {
+	case `"under-construction"`:
+		*t = UNDER_CONSTRUCTION
+	case `"on-surface"`:
+		*t = ON_SURFACE
+	case `"in-orbit"`:
+		*t = IN_ORBIT
+	case `"in-deep-space"`:
+		*t = IN_DEEP_SPACE
+	case `"jumped-in-combat"`:
+		*t = JUMPED_IN_COMBAT
+	case `"forced-jump"`:
+		*t = FORCED_JUMP
+	default:
+		return fmt.Errorf("invalid ShipStatus %q", string(b))
+	}
+	return nil
+}
+
+// ClassAbbr returns the class abbreviation for the ship, e.g. TR10S.
+func (s *ShipData) ClassAbbr() string {
+	abbr := s.Class.Char()
+	if s.Class == TR {
+		abbr += fmt.Sprintf("%d", s.Tonnage)
+	}
+	if s.Type == SUB_LIGHT || s.Type == STARBASE {
+		abbr += "S"
+	}
+	return abbr
+}
+
+// Display returns the name of the ship as it is given in orders, e.g. TR10S Mayflower.
+func (s *ShipData) Display() string {
+	return s.ClassAbbr() + " " + s.Name
+}
+
+// GetShipByName returns the ship with the given name, or nil if the
+// species doesn't have one. Like the original, case is ignored.
+func (s *SpeciesData) GetShipByName(name string) *ShipData {
+	for _, ship := range s.Ships {
+		if strings.EqualFold(ship.Name, name) {
+			return ship
+		}
+	}
+	return nil
+}
+
+// GetNamplaByName returns the named planet with the given name, or nil
+// if the species doesn't have one. Like the original, case is ignored.
+func (s *SpeciesData) GetNamplaByName(name string) *NamedPlanetData {
+	for _, nampla := range s.Namplas {
+		if strings.EqualFold(nampla.Name, name) {
+			return nampla
+		}
+	}
+	return nil
+}
+
+// Cost returns the cost, in economic units, of the ship.
+func (s *ShipData) Cost() int {
+	return s.Class.Cost(s.Tonnage, s.Type == SUB_LIGHT)
+}
+
+// Capacity returns the cargo space of the ship.
+func (s *ShipData) Capacity() int {
+	return s.Class.CarryingCapacity(s.Tonnage)
+}
+
+// CargoUsed returns the cargo space taken by the items on the ship.
+func (s *ShipData) CargoUsed() int {
+	used := 0
+	for item, quantity := range s.ItemQuantity {
+		used += quantity * Item(item).CarryCapacity()
+	}
+	return used
+}
+
+// OwnedShip is a ship and the species that owns it.
+type OwnedShip struct {
+	Species *SpeciesData
+	Ship    *ShipData
+}
+
+// GetShip returns the ship a species has with the given name, or nil.
+func (g *GalaxyData) GetShip(speciesID, name string) *ShipData {
+	sp := g.GetSpeciesByID(speciesID)
+	if sp == nil {
+		return nil
+	}
+	return sp.GetShipByName(name)
+}
+
+// AllShips returns every ship in the galaxy, sorted by species number.
+// Ships keep the order they have in the species' list.
+func (g *GalaxyData) AllShips() []OwnedShip {
+	var ships []OwnedShip
+	for _, sp := range g.sortedSpecies() {
+		for _, ship := range sp.Ships {
+			ships = append(ships, OwnedShip{Species: sp, Ship: ship})
+		}
+	}
+	return ships
+}
+
+// ShipsAt returns the ships in a star system, sorted by species number.
+// Ships that are still under construction are included.
+func (g *GalaxyData) ShipsAt(x, y, z int) []OwnedShip {
+	var ships []OwnedShip
+	for _, s := range g.AllShips() {
+		if s.Ship.X == x && s.Ship.Y == y && s.Ship.Z == z {
+			ships = append(ships, s)
+		}
+	}
+	return ships
+}
@@ -0,0 +1,180 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ShipClass is the class of a ship, from PB (picketboat) to TR (transport).
+type ShipClass int
+
+// shipClasses holds the rules for each ship class. Tonnage is divided by
+// 10,000. Starbases and transports are built in any tonnage, so their
+// tonnage and cost are for one unit of tonnage.
+var shipClasses = [NUM_SHIP_CLASSES]struct {
+	abbr, name    string
+	tonnage, cost int
+}{
+	PB: {"PB", "picketboat", 1, 100},
+	CT: {"CT", "corvette", 2, 200},
+	ES: {"ES", "escort", 5, 500},
+	FF: {"FF", "frigate", 10, 1000},
+	DD: {"DD", "destroyer", 15, 1500},
+	CL: {"CL", "light cruiser", 20, 2000},
+	CS: {"CS", "strike cruiser", 25, 2500},
+	CA: {"CA", "heavy cruiser", 30, 3000},
+	CC: {"CC", "command cruiser", 35, 3500},
+	BC: {"BC", "battlecruiser", 40, 4000},
+	BS: {"BS", "battleship", 45, 4500},
+	DN: {"DN", "dreadnought", 50, 5000},
+	SD: {"SD", "super dreadnought", 55, 5500},
+	BM: {"BM", "battlemoon", 60, 6000},
+	BW: {"BW", "battleworld", 65, 6500},
+	BR: {"BR", "battlestar", 70, 7000},
+	BA: {"BA", "starbase", 1, 100},
+	TR: {"TR", "transport", 1, 100},
+}
+
+// AllShipClasses returns every ship class, smallest warship first.
+func AllShipClasses() []ShipClass {
+	classes := make([]ShipClass, NUM_SHIP_CLASSES)
+	for i := range classes {
+		classes[i] = ShipClass(i)
+	}
+	return classes
+}
+
+// LookupShipClass returns the class for a two letter abbreviation.
+// Case is ignored.
+func LookupShipClass(abbr string) (ShipClass, bool) {
+	for i, class := range shipClasses {
+		if strings.EqualFold(class.abbr, abbr) {
+			return ShipClass(i), true
+		}
+	}
+	return 0, false
+}
+
+func (c ShipClass) valid() bool {
+	return c >= 0 && c < NUM_SHIP_CLASSES
+}
+
+// Char returns the two letter abbreviation for the class, e.g. DD.
+func (c ShipClass) Char() string {
+	if !c.valid() {
+		return "??"
+	}
+	return shipClasses[c].abbr
+}
+
+func (c ShipClass) String() string {
+	if !c.valid() {
+		return "unknown"
+	}
+	return shipClasses[c].name
+}
+
+// Tonnage returns the tonnage, divided by 10,000, of the class.
+func (c ShipClass) Tonnage() int {
+	if !c.valid() {
+		return 0
+	}
+	return shipClasses[c].tonnage
+}
+
+// Cost returns the cost, in economic units, of a ship of the class.
+// Transports and starbases
+// cost in proportion to their tonnage and sub-light ships cost three
+// quarters of the cost of the FTL version.
+func (c ShipClass) Cost(tonnage int, subLight bool) int {
+	if !c.valid() {
+		return 0
+	}
+	cost := shipClasses[c].cost
+	if c == TR || c == BA {
+		cost *= tonnage
+	}
+	if subLight && c != BA {
+		cost = (3 * cost) / 4
+	}
+	return cost
+}
+
+// MinManufacturing returns the manufacturing tech level needed to build
+// a ship of the class. Starbases are assembled in orbit and need none.
+// Manufacturing tech limits which ships can be built; it doesn't change
+// what they cost.
+func (c ShipClass) MinManufacturing(tonnage int) int {
+	if c == BA {
+		return 0
+	}
+	return 2 * tonnage
+}
+
+// CarryingCapacity returns the cargo space of a ship of the class.
+// Transports and starbases have ten times the space of a warship of the
+// same tonnage.
+func (c ShipClass) CarryingCapacity(tonnage int) int {
+	if c == TR || c == BA {
+		return 10 * tonnage
+	}
+	return tonnage
+}
+
+// MaintenanceCost returns the cost, in economic units per turn, of
+// keeping a ship of the class in service: one percent of its base cost.
+func (c ShipClass) MaintenanceCost(tonnage int, subLight bool) int {
+	return c.Cost(tonnage, subLight) / 100
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (c ShipClass) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(c.Char())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmarshals a quoted json string to the enum value.
+// Older files stored the class as a number, so numbers are accepted too.
+func (c *ShipClass) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		if !ShipClass(n).valid() {
+			return fmt.Errorf("invalid ShipClass %d", n)
+		}
+		*c = ShipClass(n)
+		return nil
+	}
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	class, ok := LookupShipClass(j)
+	if !ok {
+		return fmt.Errorf("invalid ShipClass %q", string(b))
+	}
+	*c = class
+	return nil
+}
@@ -26,27 +26,29 @@ type SpeciesData struct {
 	GovtType         string      // Type of government.
 	HomePlanet       *PlanetData `json:"-"`
 	HomeNampla       *NamedPlanetData
-	X, Y, Z          int       // Coordinates of home planet.
-	PN               int       // planet number?
-	RequiredGas      GasType   // Gas required by species.
-	RequiredGasMin   int       // Minimum needed percentage.
-	RequiredGasMax   int       // Maximum allowed percentage.
-	NeutralGas       []GasType // Gases neutral to species.
-	PoisonGas        []GasType // Gases poisonous to species.
-	AutoOrders       bool      // AUTO command was issued.
-	TechLevel        [6]int    // Actual tech levels.
-	InitTechLevel    [6]int    // Tech levels at start of turn.
-	TechKnowledge    [6]int    // Unapplied tech level knowledge.
-	NumNamplas       int       // Number of named planets, including home planet and colonies.
-	NumShips         int       // Number of ships.
-	TechEps          [6]int    // Experience points for tech levels.
-	HPOriginalBase   int       // If non-zero, home planet was bombed either by bombardment or germ warfare and has not yet fully recovered. Value is total economic base before bombing.
-	EconUnits        int       // Number of economic units.
-	FleetCost        int       // Total fleet maintenance cost.
-	FleetPercentCost int       // Fleet maintenance cost as a percentage times one hundred.
-	Contact          []bool    // A bit is set if corresponding species has been met.
-	Ally             []bool    // A bit is set if corresponding species is considered an ally.
-	Enemy            []bool    // A bit is set if corresponding species is considered an enemy.
+	X, Y, Z          int                // Coordinates of home planet.
+	PN               int                // planet number?
+	RequiredGas      GasType            // Gas required by species.
+	RequiredGasMin   int                // Minimum needed percentage.
+	RequiredGasMax   int                // Maximum allowed percentage.
+	NeutralGas       []GasType          // Gases neutral to species.
+	PoisonGas        []GasType          // Gases poisonous to species.
+	AutoOrders       bool               // AUTO command was issued.
+	TechLevel        [6]int             // Actual tech levels.
+	InitTechLevel    [6]int             // Tech levels at start of turn.
+	TechKnowledge    [6]int             // Unapplied tech level knowledge.
+	NumNamplas       int                // Number of named planets, including home planet and colonies.
+	NumShips         int                // Number of ships.
+	TechEps          [6]int             // Experience points for tech levels.
+	HPOriginalBase   int                // If non-zero, home planet was bombed either by bombardment or germ warfare and has not yet fully recovered. Value is total economic base before bombing.
+	EconUnits        int                // Number of economic units.
+	FleetCost        int                // Total fleet maintenance cost.
+	FleetPercentCost int                // Fleet maintenance cost as a percentage times one hundred.
+	Contact          SpeciesSet         // Species that have been met, by species id.
+	Ally             SpeciesSet         // Species considered an ally, by species id.
+	Enemy            SpeciesSet         // Species considered an enemy, by species id.
+	Namplas          []*NamedPlanetData `json:"namplas"` // Named planets, starting with the home planet.
+	Ships            []*ShipData        `json:"ships"`
 }
 
 /* Get life support tech level needed. */
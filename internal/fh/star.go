@@ -44,7 +44,7 @@ func XYZToID(x, y, z int) string {
 	return fmt.Sprintf("%03d/%03d/%03d", x, y, z)
 }
 
-func GenerateStar(x, y, z, nSpecies int) (*StarData, error) {
+func GenerateStar(r *RNG, x, y, z, nSpecies int) (*StarData, error) {
 	fmt.Printf("Generating star (%3d, %3d, %3d)\n", x, y, z)
 
 	/* Set coordinates. */
@@ -59,7 +59,7 @@ func GenerateStar(x, y, z, nSpecies int) (*StarData, error) {
 	}
 
 	/* Determine type of star. Make MAIN_SEQUENCE the most common star type. */
-	switch rnd(GIANT + 6) {
+	switch r.Roll(GIANT + 6) {
 	case 1:
 		star.Type = DWARF
 	case 2:
@@ -72,8 +72,8 @@ func GenerateStar(x, y, z, nSpecies int) (*StarData, error) {
 
 	/* Determine the number of planets in orbit around the star. The algorithm is something I tweaked until I liked it. It's weird, but it works. */
 	/* Color and size of star are totally random. */
-	star.Size = rnd(10) - 1
-	switch c := rnd(RED); c {
+	star.Size = r.Roll(10) - 1
+	switch c := r.Roll(RED); c {
 	case BLUE:
 		star.Color = BLUE
 	case BLUE_WHITE:
@@ -127,21 +127,21 @@ func GenerateStar(x, y, z, nSpecies int) (*StarData, error) {
 	}
 
 	for i := 1; i <= numberOfDice; i++ {
-		star.NumPlanets += rnd(sizeOfDie)
+		star.NumPlanets += r.Roll(sizeOfDie)
 	}
 	// adjust if too few or too many planets
 	for star.NumPlanets < 1 {
-		star.NumPlanets += rnd(2)
+		star.NumPlanets += r.Roll(2)
 	}
 	for star.NumPlanets > 9 {
-		star.NumPlanets -= rnd(3)
+		star.NumPlanets -= r.Roll(3)
 	}
 
 	fmt.Printf("Generating star (%3d, %3d, %3d) (type %-13s) (planets %d)\n", x, y, z, star.Type, star.NumPlanets)
 
 	// generate planets
 	var err error
-	star.Planets, err = GeneratePlanet(star.ID, star.NumPlanets)
+	star.Planets, err = GeneratePlanet(r, star.ID, star.NumPlanets)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +154,7 @@ func (s *StarData) At(x, y, z int) bool {
 }
 
 // convert the system to a system with a home planet
-func (s *StarData) ConvertToHomeSystem(src []*PlanetData) {
+func (s *StarData) ConvertToHomeSystem(r *RNG, src []*PlanetData) {
 	s.HomeSystem = true
 
 	// update the star with values from the source template
@@ -167,19 +167,19 @@ func (s *StarData) ConvertToHomeSystem(src []*PlanetData) {
 		if planet.TemperatureClass == 0 {
 			// no changes
 		} else if planet.TemperatureClass > 12 {
-			planet.TemperatureClass -= rnd(3) - 1
+			planet.TemperatureClass -= r.Roll(3) - 1
 		} else {
-			planet.TemperatureClass += rnd(3) - 1
+			planet.TemperatureClass += r.Roll(3) - 1
 		}
 		if planet.PressureClass == 0 {
 			// no changes
 		} else if planet.PressureClass > 12 {
-			planet.PressureClass -= rnd(3) - 1
+			planet.PressureClass -= r.Roll(3) - 1
 		} else {
-			planet.PressureClass += rnd(3) - 1
+			planet.PressureClass += r.Roll(3) - 1
 		}
 		if len(planet.Gases) > 2 {
-			j := rnd(25) + 10
+			j := r.Roll(25) + 10
 			a, b := 1, 2
 			if planet.Gases[b].Percentage > 50 {
 				planet.Gases[a].Percentage += j
@@ -190,19 +190,19 @@ func (s *StarData) ConvertToHomeSystem(src []*PlanetData) {
 			}
 		}
 		if planet.Diameter > 12 {
-			planet.Diameter -= rnd(3) - 1
+			planet.Diameter -= r.Roll(3) - 1
 		} else {
-			planet.Diameter += rnd(3) - 1
+			planet.Diameter += r.Roll(3) - 1
 		}
 		if planet.Gravity > 100 {
-			planet.Gravity -= rnd(10)
+			planet.Gravity -= r.Roll(10)
 		} else {
-			planet.Gravity += rnd(10)
+			planet.Gravity += r.Roll(10)
 		}
 		if planet.MiningDifficulty > 100 {
-			planet.MiningDifficulty -= rnd(10)
+			planet.MiningDifficulty -= r.Roll(10)
 		} else {
-			planet.MiningDifficulty += rnd(10)
+			planet.MiningDifficulty += r.Roll(10)
 		}
 	}
 }
@@ -283,12 +283,7 @@ func (s *StarData) Scan(w io.Writer, species *SpeciesData) error {
 
 		fmt.Fprintf(w, "\n")
 	}
-	if s.Message != 0 {
-		/* There is a message that must be logged whenever this star system is scanned. */
-		// TODO:
-		//sprintf(filename, "message%ld.txt\0", star->message);
-		//log_message(filename);
-	}
+	/* Messages attached to the star system are added to the species log by the turn, see GalaxyData.EventMessagesInSystem. */
 
 	return nil
 }
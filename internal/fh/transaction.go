@@ -0,0 +1,152 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// TransactionType is the kind of an interspecies transaction, from
+// EU_TRANSFER to ALLIES_ORDER.
+type TransactionType int
+
+var transactionTypes = map[TransactionType]string{
+	EU_TRANSFER:               "eu-transfer",
+	MESSAGE_TO_SPECIES:        "message-to-species",
+	BESIEGE_PLANET:            "besiege-planet",
+	SIEGE_EU_TRANSFER:         "siege-eu-transfer",
+	TECH_TRANSFER:             "tech-transfer",
+	DETECTION_DURING_SIEGE:    "detection-during-siege",
+	SHIP_MISHAP:               "ship-mishap",
+	ASSIMILATION:              "assimilation",
+	INTERSPECIES_CONSTRUCTION: "interspecies-construction",
+	TELESCOPE_DETECTION:       "telescope-detection",
+	ALIEN_JUMP_PORTAL_USAGE:   "alien-jump-portal-usage",
+	KNOWLEDGE_TRANSFER:        "knowledge-transfer",
+	LANDING_REQUEST:           "landing-request",
+	LOOTING_EU_TRANSFER:       "looting-eu-transfer",
+	ALLIES_ORDER:              "allies-order",
+}
+
+func (t TransactionType) String() string {
+	if s, ok := transactionTypes[t]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (t TransactionType) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(t.String())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmarshals a quoted json string to the enum value
+func (t *TransactionType) UnmarshalJSON(b []byte) error {
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	for k, v := range transactionTypes {
+		if v == j {
+			*t = k
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid TransactionType %q", string(b))
+}
+
+// Transaction is something that happened between two species during a
+// turn: a gift of economic units, a tech being taught, a message, and
+// so on. It replaces the trans_data of the original. Donor and
+// Recipient are species ids. The meaning of the numbers and names
+// depends on the type of the transaction.
+type Transaction struct {
+	Type      TransactionType `json:"type"`
+	Turn      int             `json:"turn"`
+	Donor     string          `json:"donor"`
+	Recipient string          `json:"recipient"`
+	Value     int             `json:"value,omitempty"`
+	X         int             `json:"x,omitempty"` // location associated with the transaction
+	Y         int             `json:"y,omitempty"`
+	Z         int             `json:"z,omitempty"`
+	PN        int             `json:"pn,omitempty"`
+	Number1   int             `json:"number1,omitempty"`
+	Name1     string          `json:"name1,omitempty"`
+	Number2   int             `json:"number2,omitempty"`
+	Name2     string          `json:"name2,omitempty"`
+	Number3   int             `json:"number3,omitempty"`
+	Name3     string          `json:"name3,omitempty"`
+}
+
+// Ledger holds the transactions for the current turn in the order they
+// were recorded. It is saved with the galaxy so that every phase of the
+// turn, and the reports, can see what earlier phases recorded.
+type Ledger []*Transaction
+
+// Add appends a transaction to the ledger.
+func (l *Ledger) Add(tr *Transaction) {
+	*l = append(*l, tr)
+}
+
+// Clear removes every transaction from the ledger.
+func (l *Ledger) Clear() {
+	*l = nil
+}
+
+// For returns the transactions received by a species, in the order
+// they were recorded.
+func (l Ledger) For(recipient string) []*Transaction {
+	var list []*Transaction
+	for _, tr := range l {
+		if tr.Recipient == recipient {
+			list = append(list, tr)
+		}
+	}
+	return list
+}
+
+// From returns the transactions made by a species, in the order they
+// were recorded.
+func (l Ledger) From(donor string) []*Transaction {
+	var list []*Transaction
+	for _, tr := range l {
+		if tr.Donor == donor {
+			list = append(list, tr)
+		}
+	}
+	return list
+}
+
+// OfType returns the transactions of one type, in the order they were
+// recorded.
+func (l Ledger) OfType(t TransactionType) []*Transaction {
+	var list []*Transaction
+	for _, tr := range l {
+		if tr.Type == t {
+			list = append(list, tr)
+		}
+	}
+	return list
+}
@@ -19,52 +19,87 @@
 package fh
 
 import (
+	"hash/fnv"
 	"time"
 )
 
+// The random number streams kept in the galaxy. Each part of the game
+// draws from its own stream, so that extra rolls in one (say, another
+// battle) don't change the results of another (say, jump mishaps).
+const (
+	GENERATION_STREAM = "generation"
+	JUMP_STREAM       = "jump"
+	PRODUCTION_STREAM = "production"
+	COMBAT_STREAM     = "combat"
+)
+
 /* This routine will return a random int between 1 and max, inclusive.
    It uses the so-called "Algorithm M" method, which is a combination
    of the congruential and shift-register methods. */
 
-var last_random uint64 = 1924085713 /* Random seed. */
+// RNG is a random number generator. Its seed and state are saved with
+// the galaxy, so a turn started from a saved galaxy rolls the same
+// numbers every time it is run.
+type RNG struct {
+	Seed  uint64 `json:"seed"`
+	State uint64 `json:"state"`
+}
+
+// NewRNG returns a generator for a seed. Like the original, it throws
+// away a few hundred rolls before returning.
+func NewRNG(seed uint64) *RNG {
+	r := &RNG{Seed: seed, State: seed}
+	if r.State == 0 {
+		// a state of zero would only ever produce zero
+		r.State = 1924085713
+	}
+	n := r.Roll(100) + r.Roll(200) + r.Roll(300)
+	for i := 0; i < n; i++ {
+		r.Roll(10)
+	}
+	return r
+}
+
+// NewSeed returns a seed based on the current time.
+func NewSeed() uint64 {
+	return uint64(time.Now().UnixNano())
+}
 
-func rnd(max int) int {
+// Roll returns a random number in the range 1..max.
+func (r *RNG) Roll(max int) int {
 	var a, b, c, cong_result, shift_result uint64
 
 	/* For congruential method, multiply previous value by the prime number 16417. */
-	a = last_random
-	b = last_random << 5
-	c = last_random << 14
+	a = r.State
+	b = r.State << 5
+	c = r.State << 14
 	cong_result = a + b + c /* Effectively multiply by 16417. */
 
 	/* For shift-register method, use shift-right 15 and shift-left 17 with no-carry addition (i.e., exclusive-or). */
-	a = last_random >> 15
-	shift_result = a ^ last_random
+	a = r.State >> 15
+	shift_result = a ^ r.State
 	a = shift_result << 17
 	shift_result ^= a
 
-	last_random = cong_result ^ shift_result
+	r.State = cong_result ^ shift_result
 
-	a = last_random & 0x0000FFFF
+	a = r.State & 0x0000FFFF
 
 	return int((a*uint64(max))>>16) + 1
 }
 
-// Roll returns a random number in the range 1..n.
-func Roll(max int) int {
-	return rnd(max)
-}
-
-// Seed random number generator
-func Seed(seed uint64) {
-	last_random = seed
-	n := rnd(100) + rnd(200) + rnd(300)
-	for i := 0; i < n; i++ {
-		rnd(10)
+// Stream returns the named random number stream of the galaxy, starting
+// it from the galaxy's seed the first time it is used.
+func (g *GalaxyData) Stream(name string) *RNG {
+	if r, ok := g.RNG[name]; ok {
+		return r
 	}
-}
-
-// SeedFromTime
-func SeedFromTime() {
-	Seed(uint64(time.Now().UnixNano()))
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	r := NewRNG(g.Seed ^ h.Sum64())
+	if g.RNG == nil {
+		g.RNG = make(map[string]*RNG)
+	}
+	g.RNG[name] = r
+	return r
 }
@@ -0,0 +1,105 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fh
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func rolls(r *RNG, n int) []int {
+	var list []int
+	for i := 0; i < n; i++ {
+		list = append(list, r.Roll(100))
+	}
+	return list
+}
+
+func TestRNGDeterminism(t *testing.T) {
+	// the sequence for a seed must never change, or saved games won't replay
+	if got, want := fmt.Sprint(rolls(NewRNG(12345), 8)), "[57 86 87 74 62 25 48 21]"; got != want {
+		t.Errorf("seed 12345: got %s, want %s", got, want)
+	}
+
+	for _, seed := range []uint64{0, 1, 12345, 1 << 63} {
+		a, b := NewRNG(seed), NewRNG(seed)
+		if ra, rb := fmt.Sprint(rolls(a, 100)), fmt.Sprint(rolls(b, 100)); ra != rb {
+			t.Errorf("seed %d: two generators gave different rolls", seed)
+		}
+		for _, max := range []int{1, 6, 100, 1000} {
+			for i := 0; i < 1000; i++ {
+				if n := a.Roll(max); n < 1 || n > max {
+					t.Fatalf("seed %d: Roll(%d) returned %d", seed, max, n)
+				}
+			}
+		}
+	}
+}
+
+// TestRNGStreams checks that a saved stream picks up where it left off
+// and that rolling one stream doesn't change another.
+func TestRNGStreams(t *testing.T) {
+	g := &GalaxyData{Seed: 42}
+	rolls(g.Stream(JUMP_STREAM), 10)
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprint(rolls(g.Stream(JUMP_STREAM), 10))
+
+	var saved GalaxyData
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(rolls(saved.Stream(JUMP_STREAM), 10)); got != want {
+		t.Errorf("saved jump stream: got %s, want %s", got, want)
+	}
+
+	a, b := &GalaxyData{Seed: 42}, &GalaxyData{Seed: 42}
+	rolls(a.Stream(COMBAT_STREAM), 50)
+	if ra, rb := fmt.Sprint(rolls(a.Stream(PRODUCTION_STREAM), 10)), fmt.Sprint(rolls(b.Stream(PRODUCTION_STREAM), 10)); ra != rb {
+		t.Errorf("combat rolls changed the production stream: %s, want %s", ra, rb)
+	}
+}
+
+func TestGenerateGalaxyDeterminism(t *testing.T) {
+	setup := &SetupData{}
+	setup.Galaxy.Overrides.UseOverrides = true
+	setup.Galaxy.Overrides.Radius = MIN_RADIUS
+	setup.Galaxy.Overrides.NumberOfStars = MIN_STARS
+	setup.Players = []PlayerData{{Email: "sp01@example.com", SpeciesName: "Alpha"}}
+	var galaxies []string
+	for _, seed := range []uint64{7, 7, 8} {
+		g, err := GenerateGalaxy(setup, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		galaxies = append(galaxies, string(data))
+	}
+	if galaxies[0] != galaxies[1] {
+		t.Errorf("seed 7 gave two different galaxies")
+	} else if galaxies[0] == galaxies[2] {
+		t.Errorf("seeds 7 and 8 gave the same galaxy")
+	}
+}
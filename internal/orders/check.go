@@ -0,0 +1,213 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package orders
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"sort"
+	"strings"
+)
+
+// Check resolves the names and locations used in the orders against the
+// current state of the species and returns any problems found.
+// It does not change any game data.
+//
+// Commands are checked in the order the phases run them, so a ship that
+// is built in PRODUCTION may be referred to in POST-ARRIVAL but not in
+// JUMPS.
+func Check(o *Orders, g *fh.GalaxyData, sp *fh.SpeciesData) ErrorList {
+	c := &checker{g: g, sp: sp, ships: make(map[string]int), namplas: make(map[string]bool)}
+	for _, ship := range sp.Ships {
+		c.ships[strings.ToUpper(ship.Name)] = int(ship.Class)
+	}
+	for _, nampla := range sp.Namplas {
+		c.namplas[strings.ToUpper(nampla.Name)] = true
+	}
+
+	commands := append([]*Command{}, o.Commands...)
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].Section < commands[j].Section
+	})
+	for _, cmd := range commands {
+		c.check(cmd)
+	}
+
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		return c.errors[i].Line < c.errors[j].Line
+	})
+	return c.errors
+}
+
+type checker struct {
+	g       *fh.GalaxyData
+	sp      *fh.SpeciesData
+	ships   map[string]int  // ship class by upper-cased name
+	namplas map[string]bool // named planets by upper-cased name
+	errors  ErrorList
+}
+
+func (c *checker) errorf(cmd *Command, format string, args ...interface{}) *Error {
+	err := &Error{Line: cmd.Line, Msg: fmt.Sprintf("%s: %s", fh.CommandName(cmd.Code), fmt.Sprintf(format, args...))}
+	c.errors = append(c.errors, err)
+	return err
+}
+
+func (c *checker) check(cmd *Command) {
+	for i, arg := range cmd.Args {
+		switch arg.Kind {
+		case NUMBER:
+			if arg.Number < 0 {
+				c.errorf(cmd, "%d can't be negative", arg.Number)
+			}
+		case LOCATION:
+			c.checkLocation(cmd, arg)
+		case ITEM_CLASS:
+			if item := fh.Item(arg.Code); !item.Assigned() {
+				c.errorf(cmd, "item %s is not assigned", item.Abbr())
+			} else if cmd.Code == fh.INSTALL && arg.Code != fh.IU && arg.Code != fh.AU {
+				err := c.errorf(cmd, "only IU and AU can be installed")
+				err.Hint = "use IU or AU"
+			}
+		case PLANET_ID:
+			if cmd.Code == fh.NAME {
+				if c.namplas[strings.ToUpper(arg.Name)] {
+					err := c.errorf(cmd, "planet name %q is already in use", arg.Name)
+					err.Hint = "choose a different name"
+				}
+				c.namplas[strings.ToUpper(arg.Name)] = true
+			} else if !c.namplas[strings.ToUpper(arg.Name)] {
+				err := c.errorf(cmd, "you have no planet named %q", arg.Name)
+				if name := closest(arg.Name, c.namplaNames()); name != "" {
+					err.Hint = fmt.Sprintf("did you mean %q?", "PL "+name)
+				}
+			}
+		case SPECIES_ID:
+			if other := c.getSpecies(arg.Name); other == nil {
+				err := c.errorf(cmd, "there is no species named %q", arg.Name)
+				if name := closest(arg.Name, c.speciesNames()); name != "" {
+					err.Hint = fmt.Sprintf("did you mean %q?", "SP "+name)
+				}
+			} else if other == c.sp {
+				c.errorf(cmd, "SP %s is your own species", arg.Name)
+			}
+		case SHIP:
+			if newShip := (cmd.Code == fh.BUILD || cmd.Code == fh.BASE) && i == 0; newShip {
+				c.checkNewShip(cmd, arg)
+			} else {
+				c.checkShip(cmd, arg)
+			}
+		}
+	}
+}
+
+func (c *checker) checkLocation(cmd *Command, arg *Arg) {
+	diameter := 2 * c.g.Radius
+	if arg.X < 0 || arg.X >= diameter || arg.Y < 0 || arg.Y >= diameter || arg.Z < 0 || arg.Z >= diameter {
+		err := c.errorf(cmd, "coordinates %d %d %d are outside the galaxy", arg.X, arg.Y, arg.Z)
+		err.Hint = fmt.Sprintf("coordinates must be between 0 and %d", diameter-1)
+		return
+	}
+	star := c.g.GetStarAt(arg.X, arg.Y, arg.Z)
+	if star == nil {
+		// ships may jump into deep space, but nothing else happens there
+		if cmd.Code != fh.JUMP && cmd.Code != fh.MOVE && cmd.Code != fh.PJUMP && cmd.Code != fh.BATTLE && cmd.Code != fh.HAVEN {
+			c.errorf(cmd, "there is no star system at %d %d %d", arg.X, arg.Y, arg.Z)
+		}
+		return
+	}
+	if arg.PN > star.NumPlanets {
+		err := c.errorf(cmd, "star system %d %d %d has only %d planets", arg.X, arg.Y, arg.Z, star.NumPlanets)
+		err.Hint = fmt.Sprintf("planet number must be between 1 and %d", star.NumPlanets)
+	} else if cmd.Code == fh.NAME && arg.PN == 0 {
+		err := c.errorf(cmd, "planet number is missing")
+		err.Hint = fmt.Sprintf("use %d %d %d pn, where pn is between 1 and %d", arg.X, arg.Y, arg.Z, star.NumPlanets)
+	}
+}
+
+func (c *checker) checkNewShip(cmd *Command, arg *Arg) {
+	if _, ok := c.ships[strings.ToUpper(arg.Name)]; ok {
+		err := c.errorf(cmd, "you already have a ship named %q", arg.Name)
+		if cmd.Code == fh.BUILD {
+			err.Hint = "use CONTINUE to finish a ship under construction, or choose a different name"
+		} else {
+			err.Hint = "choose a different name"
+		}
+	}
+	c.ships[strings.ToUpper(arg.Name)] = arg.Code
+}
+
+func (c *checker) checkShip(cmd *Command, arg *Arg) {
+	class, ok := c.ships[strings.ToUpper(arg.Name)]
+	if !ok {
+		err := c.errorf(cmd, "you have no ship named %q", arg.Name)
+		if name := closest(arg.Name, c.shipNames()); name != "" {
+			if ship := c.sp.GetShipByName(name); ship != nil {
+				err.Hint = fmt.Sprintf("did you mean %q?", ship.Display())
+			} else {
+				err.Hint = fmt.Sprintf("did you mean %q?", name)
+			}
+		}
+		return
+	}
+	if class != arg.Code {
+		err := c.errorf(cmd, "ship %q is not a %s", arg.Name, fh.ShipClass(arg.Code).Char())
+		if ship := c.sp.GetShipByName(arg.Name); ship != nil {
+			err.Hint = fmt.Sprintf("use %q", ship.Display())
+		}
+	}
+}
+
+// getSpecies finds a species by name, ignoring case like the original.
+func (c *checker) getSpecies(name string) *fh.SpeciesData {
+	if sp := c.g.GetSpeciesByName(name); sp != nil {
+		return sp
+	}
+	for _, sp := range c.g.Species {
+		if strings.EqualFold(sp.Name, name) {
+			return sp
+		}
+	}
+	return nil
+}
+
+func (c *checker) namplaNames() []string {
+	var names []string
+	for _, nampla := range c.sp.Namplas {
+		names = append(names, nampla.Name)
+	}
+	return names
+}
+
+func (c *checker) shipNames() []string {
+	var names []string
+	for _, ship := range c.sp.Ships {
+		names = append(names, ship.Name)
+	}
+	return names
+}
+
+func (c *checker) speciesNames() []string {
+	var names []string
+	for _, sp := range c.g.Species {
+		names = append(names, sp.Name)
+	}
+	sort.Strings(names)
+	return names
+}
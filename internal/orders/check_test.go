@@ -0,0 +1,52 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package orders
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"strings"
+	"testing"
+)
+
+func TestCheckQuantities(t *testing.T) {
+	g := &fh.GalaxyData{}
+	sp := &fh.SpeciesData{Namplas: []*fh.NamedPlanetData{{Name: "Home"}}}
+	for _, tc := range []struct {
+		cmd  *Command
+		want string // expected problem, empty if there should be none
+	}{
+		{&Command{Code: fh.RESEARCH, Args: []*Arg{{Kind: NUMBER, Number: -5000}, {Kind: TECH_ID, Code: fh.MI}}}, "-5000 can't be negative"},
+		{&Command{Code: fh.RESEARCH, Args: []*Arg{{Kind: NUMBER, Number: 0}, {Kind: TECH_ID, Code: fh.MI}}}, ""}, // spend everything available
+		{&Command{Code: fh.RESEARCH, Args: []*Arg{{Kind: NUMBER, Number: 5000}, {Kind: TECH_ID, Code: fh.MI}}}, ""},
+		{&Command{Code: fh.INSTALL, Args: []*Arg{{Kind: NUMBER, Number: -50}, {Kind: ITEM_CLASS, Code: fh.IU}, {Kind: PLANET_ID, Name: "Home"}}}, "-50 can't be negative"},
+		{&Command{Code: fh.INSTALL, Args: []*Arg{{Kind: NUMBER, Number: 0}, {Kind: ITEM_CLASS, Code: fh.IU}, {Kind: PLANET_ID, Name: "Home"}}}, ""}, // install them all
+		{&Command{Code: fh.INSTALL, Args: []*Arg{{Kind: NUMBER, Number: 50}, {Kind: ITEM_CLASS, Code: fh.IU}, {Kind: PLANET_ID, Name: "Home"}}}, ""},
+		{&Command{Code: fh.TARGET, Args: []*Arg{{Kind: NUMBER, Number: 0}}}, ""},
+		{&Command{Code: fh.TARGET, Args: []*Arg{{Kind: NUMBER, Number: -1}}}, "-1 can't be negative"},
+	} {
+		errs := Check(&Orders{Commands: []*Command{tc.cmd}}, g, sp)
+		if tc.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: want no problems, got %v", tc.cmd, errs)
+			}
+		} else if len(errs) != 1 || !strings.Contains(errs[0].Msg, tc.want) {
+			t.Errorf("%s: want %q, got %v", tc.cmd, tc.want, errs)
+		}
+	}
+}
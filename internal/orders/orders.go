@@ -0,0 +1,240 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package orders parses player orders files into a list of commands
+// that the turn phases can consume.
+package orders
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"strings"
+)
+
+// Section is the part of an orders file that a command was given in.
+type Section int
+
+const (
+	NO_SECTION    Section = 0
+	COMBAT        Section = 1
+	PRE_DEPARTURE Section = 2
+	JUMPS         Section = 3
+	PRODUCTION    Section = 4
+	POST_ARRIVAL  Section = 5
+	STRIKES       Section = 6
+)
+
+func (s Section) String() string {
+	switch s {
+	case COMBAT:
+		return "COMBAT"
+	case PRE_DEPARTURE:
+		return "PRE-DEPARTURE"
+	case JUMPS:
+		return "JUMPS"
+	case PRODUCTION:
+		return "PRODUCTION"
+	case POST_ARRIVAL:
+		return "POST-ARRIVAL"
+	case STRIKES:
+		return "STRIKES"
+	}
+	return "NONE"
+}
+
+// lookupSection returns the section for the name given on a START line.
+func lookupSection(name string) Section {
+	switch strings.ToUpper(name) {
+	case "COMBAT":
+		return COMBAT
+	case "PRE-DEPARTURE":
+		return PRE_DEPARTURE
+	case "JUMP", "JUMPS":
+		return JUMPS
+	case "PRODUCTION":
+		return PRODUCTION
+	case "POST-ARRIVAL":
+		return POST_ARRIVAL
+	case "STRIKE", "STRIKES":
+		return STRIKES
+	}
+	return NO_SECTION
+}
+
+// ArgKind is the type of a command argument.
+// The name classes share their values with the parsing constants in fh.
+type ArgKind int
+
+const (
+	TECH_ID    ArgKind = fh.TECH_ID
+	ITEM_CLASS ArgKind = fh.ITEM_CLASS
+	SHIP_CLASS ArgKind = fh.SHIP_CLASS
+	PLANET_ID  ArgKind = fh.PLANET_ID
+	SPECIES_ID ArgKind = fh.SPECIES_ID
+	NUMBER     ArgKind = 10
+	LOCATION   ArgKind = 11 // x y z with an optional planet number
+	SHIP       ArgKind = 12 // ship class followed by a ship name
+	WORD       ArgKind = 13 // anything we don't recognize
+)
+
+func (k ArgKind) String() string {
+	switch k {
+	case TECH_ID:
+		return "tech"
+	case ITEM_CLASS:
+		return "item"
+	case SHIP_CLASS:
+		return "ship class"
+	case PLANET_ID:
+		return "PL name"
+	case SPECIES_ID:
+		return "SP name"
+	case NUMBER:
+		return "number"
+	case LOCATION:
+		return "x y z"
+	case SHIP:
+		return "ship"
+	case WORD:
+		return "word"
+	}
+	return "unknown"
+}
+
+// Arg is a single argument to a command.
+type Arg struct {
+	Kind        ArgKind
+	Number      int    // value of a NUMBER
+	X, Y, Z, PN int    // coordinates of a LOCATION, PN is zero if not given
+	Name        string // name of a planet, species or ship; text of a WORD
+	Code        int    // tech id, item id or ship class
+	Tonnage     int    // tonnage of a transport, divided by 10,000
+	SubLight    bool   // ship class had the sub-light suffix
+}
+
+func (a *Arg) String() string {
+	switch a.Kind {
+	case TECH_ID:
+		return fh.TechAbbr[a.Code]
+	case ITEM_CLASS:
+		return fh.Item(a.Code).Abbr()
+	case SHIP_CLASS:
+		return a.classAbbr()
+	case PLANET_ID:
+		return "PL " + a.Name
+	case SPECIES_ID:
+		return "SP " + a.Name
+	case NUMBER:
+		return fmt.Sprintf("%d", a.Number)
+	case LOCATION:
+		if a.PN != 0 {
+			return fmt.Sprintf("%d %d %d %d", a.X, a.Y, a.Z, a.PN)
+		}
+		return fmt.Sprintf("%d %d %d", a.X, a.Y, a.Z)
+	case SHIP:
+		return a.classAbbr() + " " + a.Name
+	}
+	return a.Name
+}
+
+// isName returns true if the argument ends with a name.
+func (a *Arg) isName() bool {
+	switch a.Kind {
+	case PLANET_ID, SPECIES_ID, SHIP, WORD:
+		return true
+	}
+	return false
+}
+
+// classAbbr returns the abbreviation for the ship class, e.g. TR10S.
+func (a *Arg) classAbbr() string {
+	abbr := fh.ShipClass(a.Code).Char()
+	if a.Code == fh.TR {
+		abbr += fmt.Sprintf("%d", a.Tonnage)
+	}
+	if a.SubLight {
+		abbr += "S"
+	}
+	return abbr
+}
+
+// Command is a single order from an orders file.
+type Command struct {
+	Line    int      // line number in the orders file, starting at 1
+	Section Section  // section the command was given in
+	Code    int      // command code, fh.ALLY thru fh.WORMHOLE
+	Args    []*Arg   // arguments, in the order given
+	Text    []string // body of a MESSAGE, not including the ZZZ line
+}
+
+func (c *Command) String() string {
+	var sb strings.Builder
+	sb.WriteString(fh.CommandName(c.Code))
+	for i, arg := range c.Args {
+		// names run to the next comma, so they must be followed by one
+		if i != 0 && c.Args[i-1].isName() {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(arg.String())
+	}
+	return sb.String()
+}
+
+// Orders is the list of commands from a single orders file.
+type Orders struct {
+	Commands []*Command
+}
+
+// Section returns the commands given in a section, in file order.
+func (o *Orders) Section(s Section) []*Command {
+	if o == nil {
+		return nil
+	}
+	var commands []*Command
+	for _, c := range o.Commands {
+		if c.Section == s {
+			commands = append(commands, c)
+		}
+	}
+	return commands
+}
+
+// Error is a problem found on a single line of an orders file.
+type Error struct {
+	Line int
+	Msg  string
+	Hint string // suggested fix, if we have one
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// ErrorList is the list of problems found while parsing an orders file.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0].Error(), len(l)-1)
+}
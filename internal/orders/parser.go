@@ -0,0 +1,321 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package orders
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseFile loads and parses an orders file.
+func ParseFile(name string) (*Orders, error) {
+	fp, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return Parse(fp)
+}
+
+// Parse reads an orders file and returns the commands in it.
+// Lines that can't be parsed are skipped and reported in an ErrorList.
+// The returned Orders is never nil, even when there are errors,
+// so that the valid commands can still be executed.
+func Parse(r io.Reader) (*Orders, error) {
+	p := &parser{orders: &Orders{}}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		p.parseLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return p.orders, err
+	}
+
+	if p.message != nil {
+		p.errorf(p.message.Line, "MESSAGE is missing its ZZZ terminator")
+	}
+	if p.section != NO_SECTION {
+		p.errorf(p.sectionLine, "START %s is missing its END", p.section)
+	}
+	if p.errors != nil {
+		return p.orders, p.errors
+	}
+	return p.orders, nil
+}
+
+type parser struct {
+	orders      *Orders
+	errors      ErrorList
+	line        int
+	section     Section
+	sectionLine int
+	message     *Command // open MESSAGE command, collecting text until ZZZ
+}
+
+func (p *parser) errorf(line int, format string, args ...interface{}) *Error {
+	err := &Error{Line: line, Msg: fmt.Sprintf(format, args...)}
+	p.errors = append(p.errors, err)
+	return err
+}
+
+func (p *parser) parseLine(text string) {
+	// message text is copied verbatim until the terminator
+	if p.message != nil {
+		if fields := strings.Fields(text); len(fields) != 0 && fh.LookupCommand(fields[0]) == fh.ZZZ {
+			p.message = nil
+			return
+		}
+		p.message.Text = append(p.message.Text, text)
+		return
+	}
+
+	// strip comments and ignore blank lines
+	if i := strings.IndexByte(text, ';'); i != -1 {
+		text = text[:i]
+	}
+	toks := tokenize(text)
+	if len(toks) == 0 {
+		return
+	}
+
+	code := fh.LookupCommand(toks[0])
+	switch code {
+	case fh.UNDEFINED:
+		err := p.errorf(p.line, "unknown command %q", toks[0])
+		if name := closest(toks[0], commandNames()); name != "" {
+			err.Hint = fmt.Sprintf("did you mean %q?", name)
+		}
+		return
+	case fh.START:
+		if p.section != NO_SECTION {
+			p.errorf(p.line, "START found inside START %s", p.section)
+		}
+		if len(toks) != 2 {
+			p.errorf(p.line, "START must be followed by a section name")
+			return
+		}
+		section := lookupSection(toks[1])
+		if section == NO_SECTION {
+			err := p.errorf(p.line, "unknown section %q", toks[1])
+			if name := closest(toks[1], sectionNames()); name != "" {
+				err.Hint = fmt.Sprintf("did you mean %q?", name)
+			}
+			return
+		}
+		p.section, p.sectionLine = section, p.line
+		return
+	case fh.END:
+		if p.section == NO_SECTION {
+			p.errorf(p.line, "END found outside of a section")
+		}
+		p.section = NO_SECTION
+		return
+	case fh.ZZZ:
+		p.errorf(p.line, "ZZZ found outside of a MESSAGE")
+		return
+	}
+
+	if p.section == NO_SECTION {
+		p.errorf(p.line, "%s found outside of a section", fh.CommandName(code))
+		return
+	}
+
+	args, err := parseArgs(toks[1:])
+	if err != nil {
+		p.errorf(p.line, "%s: %v", fh.CommandName(code), err)
+		return
+	}
+
+	cmd := &Command{Line: p.line, Section: p.section, Code: code, Args: args}
+	if code == fh.MESSAGE {
+		// start collecting text even if the command is invalid so
+		// that the body isn't parsed as more commands
+		p.message = cmd
+	}
+
+	stx, ok := commandSyntax[code]
+	if !ok {
+		p.errorf(p.line, "%s is not a player command", fh.CommandName(code))
+		return
+	} else if !stx.allowedIn(p.section) {
+		p.errorf(p.line, "%s is not allowed in the %s section", fh.CommandName(code), p.section)
+		return
+	} else if resolved, ok := stx.resolve(args); ok {
+		cmd.Args = resolved
+	} else {
+		err := p.errorf(p.line, "invalid arguments for %s: expected %q", fh.CommandName(code), stx.usage)
+		for _, arg := range args {
+			if arg.Kind != WORD {
+				continue
+			}
+			word := strings.Fields(arg.Name)[0]
+			if abbr := closest(word, abbreviations()); abbr != "" {
+				err.Hint = fmt.Sprintf("%q is not an abbreviation, did you mean %q?", word, abbr)
+			} else {
+				err.Hint = fmt.Sprintf("%q is not an abbreviation, names need a PL, SP or ship class prefix", word)
+			}
+			break
+		}
+		return
+	}
+
+	p.orders.Commands = append(p.orders.Commands, cmd)
+}
+
+// tokenize splits a line into words, treating commas as separate tokens.
+func tokenize(text string) []string {
+	var toks []string
+	for _, field := range strings.Fields(text) {
+		for len(field) != 0 {
+			i := strings.IndexByte(field, ',')
+			if i == -1 {
+				toks = append(toks, field)
+				break
+			}
+			if i != 0 {
+				toks = append(toks, field[:i])
+			}
+			toks = append(toks, ",")
+			field = field[i+1:]
+		}
+	}
+	return toks
+}
+
+// parseArgs converts the tokens following a command into arguments.
+// Numbers are returned as NUMBER arguments; the command's syntax decides
+// which of them are coordinates. Names run until the next comma or the
+// end of the line.
+func parseArgs(toks []string) ([]*Arg, error) {
+	var args []*Arg
+	for i := 0; i < len(toks); {
+		if toks[i] == "," {
+			i++
+			continue
+		}
+
+		if n, ok := atoi(toks[i]); ok {
+			args = append(args, &Arg{Kind: NUMBER, Number: n})
+			i++
+			continue
+		} else if _, err := strconv.Atoi(toks[i]); err == nil {
+			return nil, fmt.Errorf("%q: amounts and coordinates can't be signed", toks[i])
+		}
+
+		word := toks[i]
+		i++
+		// collect the remainder of the phrase for names
+		var name []string
+		for j := i; j < len(toks) && toks[j] != ","; j++ {
+			name = append(name, toks[j])
+		}
+
+		switch up := strings.ToUpper(word); up {
+		case "PL", "SP":
+			if len(name) == 0 {
+				return nil, fmt.Errorf("%s must be followed by a name", up)
+			}
+			kind := PLANET_ID
+			if up == "SP" {
+				kind = SPECIES_ID
+			}
+			args = append(args, &Arg{Kind: kind, Name: strings.Join(name, " ")})
+			i += len(name)
+			continue
+		}
+
+		if tech := lookupTech(word); tech != -1 {
+			args = append(args, &Arg{Kind: TECH_ID, Code: tech})
+			continue
+		}
+
+		if item, ok := fh.LookupItem(word); ok {
+			args = append(args, &Arg{Kind: ITEM_CLASS, Code: int(item)})
+			continue
+		}
+
+		if arg, ok := parseShipClass(word); ok {
+			if len(name) != 0 {
+				arg.Kind, arg.Name = SHIP, strings.Join(name, " ")
+				i += len(name)
+			}
+			args = append(args, arg)
+			continue
+		}
+
+		args = append(args, &Arg{Kind: WORD, Name: strings.Join(append([]string{word}, name...), " ")})
+		i += len(name)
+	}
+	return args, nil
+}
+
+// parseShipClass parses abbreviations like DD, DDS, TR10, TR10S and BAS.
+func parseShipClass(word string) (*Arg, bool) {
+	if len(word) < 2 {
+		return nil, false
+	}
+	word = strings.ToUpper(word)
+	class, ok := fh.LookupShipClass(word[:2])
+	if !ok {
+		return nil, false
+	}
+	arg, rest := &Arg{Kind: SHIP_CLASS, Code: int(class), Tonnage: class.Tonnage()}, word[2:]
+	if class == fh.BA {
+		// starbases are always sub-light
+		arg.SubLight = true
+		return arg, rest == "S"
+	}
+	if strings.HasSuffix(rest, "S") {
+		arg.SubLight, rest = true, rest[:len(rest)-1]
+	}
+	if class == fh.TR {
+		tonnage, ok := atoi(rest)
+		if !ok || tonnage < 1 {
+			return nil, false
+		}
+		arg.Tonnage = tonnage
+		return arg, true
+	}
+	return arg, rest == ""
+}
+
+func lookupTech(word string) int {
+	for i, abbr := range fh.TechAbbr {
+		if strings.EqualFold(word, abbr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// atoi converts an unsigned number.
+func atoi(s string) (int, bool) {
+	if s == "" || strings.IndexByte("0123456789", s[0]) == -1 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
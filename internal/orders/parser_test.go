@@ -0,0 +1,107 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package orders
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseCommands(t *testing.T) {
+	for _, tc := range []struct {
+		section string
+		line    string
+		want    string // command as printed, or the expected error
+	}{
+		{"COMBAT", "Battle 10 20 30", "Battle 10 20 30"},
+		{"COMBAT", "Withdraw 10 20 30", "Withdraw 10 20 30"},
+		{"COMBAT", "Withdraw 10 20", `invalid arguments for Withdraw`},
+		{"COMBAT", "Engage 4, 3", "Engage 4 3"},
+		{"JUMPS", "Jump DD Alpha, 10 20 30", "Jump DD Alpha, 10 20 30"},
+		{"JUMPS", "Jump DD Alpha, 10 20 30 4", "Jump DD Alpha, 10 20 30 4"},
+		{"JUMPS", "Jump DD Alpha, 10 20 30 12", `invalid arguments for Jump`},
+		{"JUMPS", "Jump DD Alpha, PL Home", "Jump DD Alpha, PL Home"},
+		{"PRE-DEPARTURE", "Name 10 20 30 2 PL New Home", "Name 10 20 30 2 PL New Home"},
+		{"PRE-DEPARTURE", "Repair 10 20 30", "Repair 10 20 30"},
+		{"PRE-DEPARTURE", "Repair 10 20 30, 5", "Repair 10 20 30 5"},
+		{"PRE-DEPARTURE", "Install 50 IU PL Home", "Install 50 IU PL Home"},
+		{"PRE-DEPARTURE", "Install -50 IU PL Home", `"-50": amounts and coordinates can't be signed`},
+		{"PRE-DEPARTURE", "Transfer 10 CU PL Home, TR10 Cargo", "Transfer 10 CU PL Home, TR10 Cargo"},
+		{"PRE-DEPARTURE", "Transfer +10 CU PL Home, TR10 Cargo", `"+10": amounts and coordinates can't be signed`},
+		{"PRODUCTION", "Research 5000 MI", "Research 5000 MI"},
+		{"PRODUCTION", "Research -5000 MI", `"-5000": amounts and coordinates can't be signed`},
+		{"PRODUCTION", "Build 10 CU", "Build 10 CU"},
+		{"PRODUCTION", "Build TR10S Cargo", "Build TR10S Cargo"},
+		{"PRODUCTION", "Build TR-10 Cargo", `invalid arguments for Build`},
+		{"PRODUCTION", "Recycle 10 20 30", `invalid arguments for Recycle`},
+		{"PRODUCTION", "Battle 10 20 30", `Battle is not allowed in the PRODUCTION section`},
+	} {
+		text := fmt.Sprintf("START %s\n%s\nEND\n", tc.section, tc.line)
+		o, err := Parse(strings.NewReader(text))
+		var got string
+		if err != nil {
+			got = err.Error()
+		} else if len(o.Commands) != 1 {
+			got = fmt.Sprintf("%d commands", len(o.Commands))
+		} else {
+			got = o.Commands[0].String()
+		}
+		if err != nil && strings.Contains(got, tc.want) {
+			continue
+		} else if got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.line, tc.want, got)
+		}
+	}
+}
+
+// TestCommandSignatures checks that every form of every command can be
+// matched, with coordinates given as plain numbers.
+func TestCommandSignatures(t *testing.T) {
+	for code, stx := range commandSyntax {
+		for _, form := range stx.forms {
+			var args []*Arg
+			for _, kind := range form {
+				switch kind {
+				case LOCATION:
+					args = append(args, &Arg{Kind: NUMBER, Number: 1}, &Arg{Kind: NUMBER, Number: 2}, &Arg{Kind: NUMBER, Number: 3})
+				case NUMBER:
+					args = append(args, &Arg{Kind: NUMBER, Number: 4})
+				default:
+					args = append(args, &Arg{Kind: kind})
+				}
+			}
+			resolved, ok := resolveForm(form, args)
+			if !ok {
+				t.Errorf("%d %q: form %v does not match", code, stx.usage, form)
+				continue
+			}
+			var kinds []ArgKind
+			for _, arg := range resolved {
+				kinds = append(kinds, arg.Kind)
+			}
+			if fmt.Sprint(kinds) != fmt.Sprint(form) {
+				t.Errorf("%d %q: form %v resolved to %v", code, stx.usage, form, kinds)
+			}
+			if _, ok := stx.resolve(args); !ok {
+				t.Errorf("%d %q: arguments for form %v are rejected", code, stx.usage, form)
+			}
+		}
+	}
+}
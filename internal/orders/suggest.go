@@ -0,0 +1,85 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package orders
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"strings"
+)
+
+// closest returns the candidate that is the fewest edits away from the
+// word, or an empty string if nothing is close enough to be a typo.
+func closest(word string, candidates []string) string {
+	word = strings.ToUpper(word)
+	best, bestDistance := "", len(word)/2+1
+	for _, candidate := range candidates {
+		if d := distance(word, strings.ToUpper(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance between two strings.
+func distance(a, b string) int {
+	prev, curr := make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func commandNames() []string {
+	var names []string
+	for code := fh.ALLY; code < fh.NUM_COMMANDS; code++ {
+		names = append(names, fh.CommandName(code))
+	}
+	return names
+}
+
+func sectionNames() []string {
+	var names []string
+	for s := COMBAT; s <= STRIKES; s++ {
+		names = append(names, s.String())
+	}
+	return names
+}
+
+// abbreviations returns the tech, item and ship class abbreviations.
+func abbreviations() []string {
+	names := append([]string{}, fh.TechAbbr...)
+	for _, item := range fh.AllItems() {
+		names = append(names, item.Abbr())
+	}
+	for _, class := range fh.AllShipClasses() {
+		names = append(names, class.Char())
+	}
+	return names
+}
@@ -0,0 +1,164 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package orders
+
+import "github.com/mdhender/farHorizons/internal/fh"
+
+// syntax describes the valid forms of a command and the sections it may appear in.
+type syntax struct {
+	usage    string
+	forms    [][]ArgKind
+	sections []Section
+}
+
+var (
+	combatSections = []Section{COMBAT, STRIKES}
+	preSections    = []Section{PRE_DEPARTURE}
+	jumpSections   = []Section{JUMPS}
+	proSections    = []Section{PRODUCTION}
+	postSections   = []Section{POST_ARRIVAL}
+	movesSections  = []Section{PRE_DEPARTURE, POST_ARRIVAL}
+)
+
+var commandSyntax = map[int]*syntax{
+	fh.ALLY:   {"Ally SP name", [][]ArgKind{{SPECIES_ID}, {NUMBER}}, movesSections},
+	fh.AMBUSH: {"Ambush amount", [][]ArgKind{{NUMBER}}, proSections},
+	fh.ATTACK: {"Attack SP name", [][]ArgKind{{SPECIES_ID}, {NUMBER}}, combatSections},
+	fh.AUTO:   {"Auto", [][]ArgKind{{}}, movesSections},
+	fh.BASE: {"Base ship, [amount,] ship or PL name", [][]ArgKind{
+		{SHIP, SHIP}, {SHIP, PLANET_ID},
+		{SHIP, NUMBER, SHIP}, {SHIP, NUMBER, PLANET_ID}}, movesSections},
+	fh.BATTLE: {"Battle x y z", [][]ArgKind{{LOCATION}}, combatSections},
+	fh.BUILD: {"Build amount item [, ship or PL name] | Build ship", [][]ArgKind{
+		{NUMBER, ITEM_CLASS}, {NUMBER, ITEM_CLASS, SHIP}, {NUMBER, ITEM_CLASS, PLANET_ID},
+		{SHIP}, {NUMBER, SHIP_CLASS}}, proSections},
+	fh.CONTINUE: {"Continue ship [, amount]", [][]ArgKind{{SHIP}, {SHIP, NUMBER}}, proSections},
+	fh.DEEP:     {"Deep ship", [][]ArgKind{{SHIP}}, movesSections},
+	fh.DESTROY:  {"Destroy ship | Destroy PL name", [][]ArgKind{{SHIP}, {PLANET_ID}}, movesSections},
+	fh.DEVELOP: {"Develop [amount] [PL name [, ship]]", [][]ArgKind{
+		{}, {NUMBER}, {PLANET_ID}, {NUMBER, PLANET_ID},
+		{PLANET_ID, SHIP}, {NUMBER, PLANET_ID, SHIP}}, proSections},
+	fh.DISBAND:   {"Disband PL name", [][]ArgKind{{PLANET_ID}}, preSections},
+	fh.ENEMY:     {"Enemy SP name", [][]ArgKind{{SPECIES_ID}, {NUMBER}}, movesSections},
+	fh.ENGAGE:    {"Engage option [, planet number]", [][]ArgKind{{NUMBER}, {NUMBER, NUMBER}}, combatSections},
+	fh.ESTIMATE:  {"Estimate SP name", [][]ArgKind{{SPECIES_ID}}, proSections},
+	fh.HAVEN:     {"Haven x y z", [][]ArgKind{{LOCATION}}, combatSections},
+	fh.HIDE:      {"Hide PL name", [][]ArgKind{{PLANET_ID}}, []Section{COMBAT, STRIKES, PRE_DEPARTURE, PRODUCTION}},
+	fh.HIJACK:    {"Hijack SP name", [][]ArgKind{{SPECIES_ID}, {NUMBER}}, combatSections},
+	fh.IBUILD:    {"IBuild amount item, SP name | IBuild ship, SP name", [][]ArgKind{{NUMBER, ITEM_CLASS, SPECIES_ID}, {SHIP, SPECIES_ID}}, proSections},
+	fh.ICONTINUE: {"IContinue ship [, amount], SP name", [][]ArgKind{{SHIP, SPECIES_ID}, {SHIP, NUMBER, SPECIES_ID}}, proSections},
+	fh.INSTALL: {"Install [amount] IU or AU, PL name", [][]ArgKind{
+		{NUMBER, ITEM_CLASS, PLANET_ID}, {ITEM_CLASS, PLANET_ID}, {PLANET_ID}}, movesSections},
+	fh.INTERCEPT: {"Intercept amount", [][]ArgKind{{NUMBER}}, proSections},
+	fh.JUMP:      {"Jump ship, x y z [pn] | Jump ship, PL name", [][]ArgKind{{SHIP, LOCATION}, {SHIP, PLANET_ID}}, jumpSections},
+	fh.LAND:      {"Land ship [, PL name]", [][]ArgKind{{SHIP}, {SHIP, PLANET_ID}, {SHIP, NUMBER}}, movesSections},
+	fh.MESSAGE:   {"Message SP name", [][]ArgKind{{SPECIES_ID}}, movesSections},
+	fh.MOVE:      {"Move ship, x y z", [][]ArgKind{{SHIP, LOCATION}}, jumpSections},
+	fh.NAME:      {"Name x y z pn PL name", [][]ArgKind{{LOCATION, PLANET_ID}}, movesSections},
+	fh.NEUTRAL:   {"Neutral SP name", [][]ArgKind{{SPECIES_ID}, {NUMBER}}, movesSections},
+	fh.ORBIT:     {"Orbit ship [, PL name]", [][]ArgKind{{SHIP}, {SHIP, PLANET_ID}, {SHIP, NUMBER}}, movesSections},
+	fh.PJUMP: {"PJump ship, x y z [pn], jump portal", [][]ArgKind{
+		{SHIP, LOCATION, SHIP}, {SHIP, PLANET_ID, SHIP},
+		{SHIP, LOCATION, PLANET_ID}, {SHIP, PLANET_ID, PLANET_ID}}, jumpSections},
+	fh.PRODUCTION: {"Production PL name", [][]ArgKind{{PLANET_ID}}, proSections},
+	fh.RECYCLE:    {"Recycle amount item | Recycle ship", [][]ArgKind{{NUMBER, ITEM_CLASS}, {SHIP}}, proSections},
+	fh.REPAIR: {"Repair ship [, amount] | Repair x y z [, age]", [][]ArgKind{
+		{SHIP}, {SHIP, NUMBER}, {LOCATION, NUMBER}, {LOCATION}}, movesSections},
+	fh.RESEARCH:  {"Research amount tech", [][]ArgKind{{NUMBER, TECH_ID}}, proSections},
+	fh.SCAN:      {"Scan ship", [][]ArgKind{{SHIP}}, movesSections},
+	fh.SEND:      {"Send amount, SP name", [][]ArgKind{{NUMBER, SPECIES_ID}}, movesSections},
+	fh.SHIPYARD:  {"Shipyard", [][]ArgKind{{}}, proSections},
+	fh.SUMMARY:   {"Summary", [][]ArgKind{{}}, combatSections},
+	fh.SURRENDER: {"Surrender SP name", [][]ArgKind{{}, {SPECIES_ID}}, combatSections},
+	fh.TARGET:    {"Target option", [][]ArgKind{{NUMBER}}, combatSections},
+	fh.TEACH: {"Teach tech [, level], SP name", [][]ArgKind{
+		{TECH_ID, SPECIES_ID}, {TECH_ID, NUMBER, SPECIES_ID}}, movesSections},
+	fh.TECH:      {"Tech tech, level", [][]ArgKind{{TECH_ID, NUMBER}}, proSections},
+	fh.TELESCOPE: {"Telescope ship", [][]ArgKind{{SHIP}}, postSections},
+	fh.TERRAFORM: {"Terraform [amount] PL name", [][]ArgKind{{PLANET_ID}, {NUMBER, PLANET_ID}}, movesSections},
+	fh.TRANSFER: {"Transfer amount item, source, destination", [][]ArgKind{
+		{NUMBER, ITEM_CLASS, SHIP, SHIP}, {NUMBER, ITEM_CLASS, SHIP, PLANET_ID},
+		{NUMBER, ITEM_CLASS, PLANET_ID, SHIP}, {NUMBER, ITEM_CLASS, PLANET_ID, PLANET_ID}}, movesSections},
+	fh.UNLOAD:   {"Unload ship", [][]ArgKind{{SHIP}}, movesSections},
+	fh.UPGRADE:  {"Upgrade ship [, amount]", [][]ArgKind{{SHIP}, {SHIP, NUMBER}}, proSections},
+	fh.VISITED:  {"Visited x y z", [][]ArgKind{{LOCATION}}, jumpSections},
+	fh.WITHDRAW: {"Withdraw transport age, warship age, percentage", [][]ArgKind{{NUMBER, NUMBER, NUMBER}}, combatSections},
+	fh.WORMHOLE: {"Wormhole ship [, PL name]", [][]ArgKind{{SHIP}, {SHIP, PLANET_ID}}, jumpSections},
+}
+
+// resolve matches the arguments against the forms and returns them with
+// the numbers the matching form expects as coordinates grouped into
+// LOCATION arguments. A location is three numbers, or four when the
+// fourth is a valid planet number and the rest of the form still matches.
+func (s *syntax) resolve(args []*Arg) ([]*Arg, bool) {
+	for _, form := range s.forms {
+		if resolved, ok := resolveForm(form, args); ok {
+			return resolved, true
+		}
+	}
+	return nil, false
+}
+
+func resolveForm(form []ArgKind, args []*Arg) ([]*Arg, bool) {
+	if len(form) == 0 {
+		return nil, len(args) == 0
+	} else if len(args) == 0 {
+		return nil, false
+	}
+	if form[0] != LOCATION {
+		if args[0].Kind != form[0] {
+			return nil, false
+		}
+		rest, ok := resolveForm(form[1:], args[1:])
+		if !ok {
+			return nil, false
+		}
+		return append([]*Arg{args[0]}, rest...), true
+	}
+	for _, size := range []int{4, 3} {
+		if len(args) < size {
+			continue
+		}
+		numbers := true
+		for _, arg := range args[:size] {
+			numbers = numbers && arg.Kind == NUMBER
+		}
+		if !numbers || (size == 4 && (args[3].Number < 1 || args[3].Number > 9)) {
+			continue
+		}
+		if rest, ok := resolveForm(form[1:], args[size:]); ok {
+			loc := &Arg{Kind: LOCATION, X: args[0].Number, Y: args[1].Number, Z: args[2].Number}
+			if size == 4 {
+				loc.PN = args[3].Number
+			}
+			return append([]*Arg{loc}, rest...), true
+		}
+	}
+	return nil, false
+}
+
+// allowedIn returns true if the command may be given in the section.
+func (s *syntax) allowedIn(section Section) bool {
+	for _, allowed := range s.sections {
+		if allowed == section {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,735 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/common"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"sort"
+	"strings"
+)
+
+/* Maximum number of rounds fought in each stage of a battle. */
+const MAX_ROUNDS = 10
+
+// Combat runs the COMBAT section of every species' orders.
+func (t *Turn) Combat() error {
+	return t.fight(orders.COMBAT)
+}
+
+// Strikes runs the STRIKES section of every species' orders.
+// Strikes use the same rules as combat but happen after movement.
+func (t *Turn) Strikes() error {
+	return t.fight(orders.STRIKES)
+}
+
+// battleOrders are the orders one species gave for one battle location.
+type battleOrders struct {
+	engage                  []engageOption
+	attack                  map[string]bool // species to attack, by id
+	attackAll               bool            // attack every species that is not an ally
+	summaryOnly             bool
+	transportWithdrawAge    int
+	warshipWithdrawAge      int
+	fleetWithdrawPercentage int
+	haven                   bool
+	havenX, havenY, havenZ  int
+	specialTarget           int
+}
+
+// engageOption is one ENGAGE order.
+type engageOption struct {
+	action int // common.DEFENSE_IN_PLACE thru common.SIEGE
+	pn     int // planet number for the planet actions
+}
+
+func newBattleOrders() *battleOrders {
+	return &battleOrders{
+		attack:                  make(map[string]bool),
+		transportWithdrawAge:    100,
+		warshipWithdrawAge:      100,
+		fleetWithdrawPercentage: 100,
+	}
+}
+
+// battle is a location where at least one species gave BATTLE orders.
+type battle struct {
+	t        *Turn
+	rng      *fh.RNG
+	x, y, z  int
+	orders   map[string]*battleOrders // by species id
+	species  []*fh.SpeciesData        // species with units at the location
+	units    []*combatant
+	tonnage  map[string]int // starting tonnage of each species' ships
+	lost     map[string]int // tonnage lost by each species
+	withdrew map[string]bool
+}
+
+// combatant is a ship or named planet taking part in a battle.
+type combatant struct {
+	species    *fh.SpeciesData
+	unitType   int // common.SHIP or common.NAMPLA
+	ship       *fh.ShipData
+	nampla     *fh.NamedPlanetData
+	shots      int
+	weapon     int // damage done by each shot
+	shield     int // damage absorbed each round
+	shieldLeft int
+	hull       int // damage needed to destroy a ship
+	damage     int
+	pdsLost    int
+	destroyed  bool
+	withdrawn  bool
+}
+
+func (c *combatant) String() string {
+	if c.unitType == common.SHIP {
+		return fmt.Sprintf("SP %s's %s", c.species.Name, c.ship.Display())
+	}
+	return fmt.Sprintf("SP %s's PL %s", c.species.Name, c.nampla.Name)
+}
+
+// active returns true if the unit is still in the battle.
+func (c *combatant) active() bool {
+	return !c.destroyed && !c.withdrawn
+}
+
+// isTransport returns true for transports, which carry no weapons.
+func (c *combatant) isTransport() bool {
+	return c.unitType == common.SHIP && c.ship.Class == fh.TR
+}
+
+func (t *Turn) fight(section orders.Section) error {
+	battles := t.getBattles(section)
+	for _, b := range battles {
+		b.resolve()
+	}
+	return nil
+}
+
+// getBattles collects the battle orders of every species, grouped by location.
+func (t *Turn) getBattles(section orders.Section) []*battle {
+	byLocation := make(map[string]*battle)
+	var battles []*battle
+	for _, sp := range t.AllSpecies() {
+		var current *battleOrders
+		for _, cmd := range t.Commands(sp, section) {
+			if cmd.Code == fh.BATTLE {
+				loc := cmd.Args[0]
+				id := fh.XYZToID(loc.X, loc.Y, loc.Z)
+				b, ok := byLocation[id]
+				if !ok {
+					b = &battle{t: t, rng: t.Galaxy.Stream(fh.COMBAT_STREAM), x: loc.X, y: loc.Y, z: loc.Z, orders: make(map[string]*battleOrders)}
+					byLocation[id] = b
+					battles = append(battles, b)
+				}
+				current = newBattleOrders()
+				b.orders[sp.ID] = current
+				continue
+			} else if current == nil {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: no BATTLE order given.\n", cmd.Line, cmd)
+				continue
+			}
+
+			switch cmd.Code {
+			case fh.ATTACK, fh.HIJACK:
+				// hijacking is fought as a normal attack
+				if arg := cmd.Args[0]; arg.Kind == orders.NUMBER && arg.Number == 0 {
+					current.attackAll = true
+				} else if other := t.getSpecies(arg); other == nil {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: unknown species.\n", cmd.Line, cmd)
+				} else {
+					current.attack[other.ID] = true
+				}
+			case fh.ENGAGE:
+				option := engageOption{action: cmd.Args[0].Number}
+				if len(cmd.Args) > 1 {
+					option.pn = cmd.Args[1].Number
+				}
+				if option.action < common.DEFENSE_IN_PLACE || option.action > common.SIEGE {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: invalid engagement option.\n", cmd.Line, cmd)
+				} else if option.action >= common.PLANET_ATTACK && option.pn == 0 {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: missing planet number.\n", cmd.Line, cmd)
+				} else if len(current.engage) >= common.MAX_ENGAGE_OPTIONS {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: too many engagement options.\n", cmd.Line, cmd)
+				} else {
+					current.engage = append(current.engage, option)
+				}
+			case fh.HAVEN:
+				current.haven = true
+				current.havenX, current.havenY, current.havenZ = cmd.Args[0].X, cmd.Args[0].Y, cmd.Args[0].Z
+			case fh.HIDE:
+				if nampla := sp.GetNamplaByName(cmd.Args[0].Name); nampla == nil {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: unknown planet.\n", cmd.Line, cmd)
+				} else {
+					nampla.Hiding = true
+				}
+			case fh.SUMMARY:
+				current.summaryOnly = true
+			case fh.TARGET:
+				if n := cmd.Args[0].Number; n < common.TARGET_WARSHIPS || n > common.TARGET_PDS {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: invalid target.\n", cmd.Line, cmd)
+				} else {
+					current.specialTarget = n
+				}
+			case fh.WITHDRAW:
+				current.transportWithdrawAge = cmd.Args[0].Number
+				current.warshipWithdrawAge = cmd.Args[1].Number
+				current.fleetWithdrawPercentage = cmd.Args[2].Number
+			}
+		}
+	}
+	return battles
+}
+
+// wantsToFight returns true if species a will attack species d at this battle.
+func (b *battle) wantsToFight(a, d *fh.SpeciesData) bool {
+	if a == d {
+		return false
+	}
+	if b.t.LocationIndex().Hostile(a, d, b.x, b.y, b.z) {
+		return true
+	}
+	bo, ok := b.orders[a.ID]
+	if !ok {
+		return false
+	}
+	return bo.attack[d.ID] || (bo.attackAll && !a.IsAlly(d))
+}
+
+// engaged returns true if the two species are fighting each other.
+func (b *battle) engaged(a, d *fh.SpeciesData) bool {
+	return b.wantsToFight(a, d) || b.wantsToFight(d, a)
+}
+
+// ordersFor returns the orders for a species, using defaults when none were given.
+func (b *battle) ordersFor(sp *fh.SpeciesData) *battleOrders {
+	if bo, ok := b.orders[sp.ID]; ok {
+		return bo
+	}
+	bo := newBattleOrders()
+	b.orders[sp.ID] = bo
+	return bo
+}
+
+// engageOptions returns the ENGAGE orders for a species. A species that
+// attacks without giving any ENGAGE orders fights in deep space, and one
+// that doesn't attack defends in place.
+func (b *battle) engageOptions(sp *fh.SpeciesData) []engageOption {
+	if options := b.ordersFor(sp).engage; len(options) != 0 {
+		return options
+	}
+	for _, other := range b.species {
+		if b.wantsToFight(sp, other) {
+			return []engageOption{{action: common.DEEP_SPACE_FIGHT}}
+		}
+	}
+	return []engageOption{{action: common.DEFENSE_IN_PLACE}}
+}
+
+// logf writes to the log of every species in the battle that didn't ask for a summary.
+func (b *battle) logf(format string, args ...interface{}) {
+	for _, sp := range b.species {
+		if !b.ordersFor(sp).summaryOnly {
+			b.t.Logf(sp, format, args...)
+		}
+	}
+}
+
+// resolve fights the battle at the location.
+func (b *battle) resolve() {
+	b.gatherUnits()
+
+	// a battle needs at least two species that are fighting each other
+	var fighting bool
+	for _, a := range b.species {
+		for _, d := range b.species {
+			if b.engaged(a, d) {
+				fighting = true
+			}
+		}
+	}
+	for id := range b.orders {
+		sp := b.t.Galaxy.GetSpeciesByID(id)
+		if !b.present(sp) {
+			b.t.Logf(sp, "\nYou gave BATTLE orders for %d %d %d but you have nothing there.\n", b.x, b.y, b.z)
+		} else if !fighting {
+			b.t.Logf(sp, "\nNo enemies were found at %d %d %d. There was no battle.\n", b.x, b.y, b.z)
+		}
+	}
+	if !fighting {
+		return
+	}
+
+	b.logf("\nBattle at x = %d, y = %d, z = %d:\n", b.x, b.y, b.z)
+	for _, sp := range b.species {
+		b.logf("  SP %s is present with %d ships and %d planets.\n", sp.Name, b.count(sp, common.SHIP), b.count(sp, common.NAMPLA))
+	}
+
+	b.withdrawOldShips()
+
+	// deep space combat happens first, if anyone wants it
+	var spaceFight bool
+	for _, sp := range b.species {
+		for _, option := range b.engageOptions(sp) {
+			if option.action >= common.DEEP_SPACE_FIGHT {
+				spaceFight = true
+			}
+		}
+	}
+	if spaceFight {
+		var units []*combatant
+		for _, u := range b.units {
+			if u.unitType == common.SHIP && u.ship.Status != fh.ON_SURFACE {
+				units = append(units, u)
+			}
+		}
+		b.logf("\n  Deep space combat:\n")
+		b.fightRounds(units)
+	}
+
+	// then attacks on planets, in the order given
+	for _, sp := range b.species {
+		for _, option := range b.engageOptions(sp) {
+			if option.action >= common.PLANET_ATTACK {
+				b.attackPlanet(sp, option)
+			}
+		}
+	}
+
+	b.summarize()
+	b.removeDestroyedShips()
+}
+
+// gatherUnits finds every ship and named planet at the battle location.
+func (b *battle) gatherUnits() {
+	b.tonnage, b.lost, b.withdrew = make(map[string]int), make(map[string]int), make(map[string]bool)
+	for _, sp := range b.t.LocationIndex().SpeciesAt(b.x, b.y, b.z) {
+		var present bool
+		for _, ship := range sp.Ships {
+			if ship.X != b.x || ship.Y != b.y || ship.Z != b.z || ship.Status == fh.UNDER_CONSTRUCTION {
+				continue
+			}
+			b.units = append(b.units, newShipCombatant(sp, ship))
+			b.tonnage[sp.ID] += ship.Tonnage
+			present = true
+		}
+		for _, nampla := range sp.Namplas {
+			if nampla.X != b.x || nampla.Y != b.y || nampla.Z != b.z {
+				continue
+			}
+			b.units = append(b.units, newNamplaCombatant(sp, nampla))
+			present = true
+		}
+		if present {
+			b.species = append(b.species, sp)
+		}
+	}
+}
+
+func newShipCombatant(sp *fh.SpeciesData, ship *fh.ShipData) *combatant {
+	// auxiliary guns and shields count as extra tonnage
+	var guns, shields int
+	for mark := 1; mark <= 9; mark++ {
+		guns += 5 * mark * ship.ItemQuantity[fh.GU1+mark-1]
+		shields += 5 * mark * ship.ItemQuantity[fh.SG1+mark-1]
+	}
+	if ship.Class != fh.TR {
+		guns += ship.Tonnage
+	}
+	return &combatant{
+		species:  sp,
+		unitType: common.SHIP,
+		ship:     ship,
+		shots:    1 + guns/10,
+		weapon:   (2 * guns * sp.TechLevel[fh.ML]) / (1 + guns/10),
+		shield:   (ship.Tonnage + shields) * sp.TechLevel[fh.LS],
+		hull:     20 * ship.Tonnage,
+	}
+}
+
+func newNamplaCombatant(sp *fh.SpeciesData, nampla *fh.NamedPlanetData) *combatant {
+	// ten planetary defense units fight like one ton of warship
+	guns := nampla.ItemQuantity[fh.PD] / 10
+	return &combatant{
+		species:  sp,
+		unitType: common.NAMPLA,
+		nampla:   nampla,
+		shots:    1 + guns/10,
+		weapon:   (2 * guns * sp.TechLevel[fh.ML]) / (1 + guns/10),
+		shield:   guns * sp.TechLevel[fh.LS],
+	}
+}
+
+func (b *battle) present(sp *fh.SpeciesData) bool {
+	for _, s := range b.species {
+		if s == sp {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *battle) count(sp *fh.SpeciesData, unitType int) int {
+	n := 0
+	for _, u := range b.units {
+		if u.species == sp && u.unitType == unitType {
+			n++
+		}
+	}
+	return n
+}
+
+// withdrawOldShips pulls ships older than the WITHDRAW ages out of the battle.
+func (b *battle) withdrawOldShips() {
+	for _, u := range b.units {
+		if u.unitType != common.SHIP {
+			continue
+		}
+		bo := b.ordersFor(u.species)
+		age := bo.warshipWithdrawAge
+		if u.isTransport() {
+			age = bo.transportWithdrawAge
+		}
+		if u.ship.Age > age {
+			b.withdraw(u)
+		}
+	}
+}
+
+// withdraw takes a ship out of the battle, sending it to the haven if one was given.
+func (b *battle) withdraw(u *combatant) {
+	u.withdrawn = true
+	bo := b.ordersFor(u.species)
+	if bo.haven {
+		u.ship.DestX, u.ship.DestY, u.ship.DestZ = bo.havenX, bo.havenY, bo.havenZ
+		u.ship.X, u.ship.Y, u.ship.Z, u.ship.PN = bo.havenX, bo.havenY, bo.havenZ, 0
+		u.ship.Status = fh.JUMPED_IN_COMBAT
+		b.logf("    %s withdraws to %d %d %d.\n", u, bo.havenX, bo.havenY, bo.havenZ)
+	} else {
+		u.ship.Status = fh.IN_DEEP_SPACE
+		b.logf("    %s withdraws from the battle.\n", u)
+	}
+}
+
+// fightRounds fights rounds until one side is gone or MAX_ROUNDS is reached.
+func (b *battle) fightRounds(units []*combatant) {
+	for round := 1; round <= MAX_ROUNDS; round++ {
+		var shooters []*combatant
+		for _, u := range units {
+			if u.active() && u.weapon > 0 && len(b.targets(u, units)) != 0 {
+				shooters = append(shooters, u)
+			}
+		}
+		if len(shooters) == 0 {
+			if round == 1 {
+				b.logf("    No shots were fired.\n")
+			}
+			return
+		}
+
+		b.logf("    Round %d:\n", round)
+		for _, u := range units {
+			u.shieldLeft = u.shield
+		}
+		for _, u := range shooters {
+			for shot := 0; shot < u.shots; shot++ {
+				targets := b.targets(u, units)
+				if !u.active() || len(targets) == 0 {
+					break
+				}
+				b.shoot(u, targets[b.rng.Roll(len(targets))-1])
+			}
+		}
+		b.checkFleetWithdrawal(units)
+	}
+}
+
+// targets returns the units that an attacker may shoot at, honoring TARGET orders.
+func (b *battle) targets(u *combatant, units []*combatant) []*combatant {
+	var targets, special []*combatant
+	want := b.ordersFor(u.species).specialTarget
+	for _, d := range units {
+		if !d.active() || !b.engaged(u.species, d.species) {
+			continue
+		} else if d.unitType == common.NAMPLA && (d.nampla.Hidden || d.nampla.ItemQuantity[fh.PD] == 0) {
+			// there is nothing left to shoot at on an undefended planet
+			continue
+		}
+		targets = append(targets, d)
+		switch {
+		case want == common.TARGET_WARSHIPS && d.unitType == common.SHIP && !d.isTransport() && d.ship.Class != fh.BA:
+			special = append(special, d)
+		case want == common.TARGET_TRANSPORTS && d.isTransport():
+			special = append(special, d)
+		case want == common.TARGET_STARBASES && d.unitType == common.SHIP && d.ship.Class == fh.BA:
+			special = append(special, d)
+		case want == common.TARGET_PDS && d.unitType == common.NAMPLA:
+			special = append(special, d)
+		}
+	}
+	if len(special) != 0 {
+		return special
+	}
+	return targets
+}
+
+// shoot fires one shot. The chance to hit depends on the difference in military tech.
+func (b *battle) shoot(u, d *combatant) {
+	chance := 50 + 2*(u.species.TechLevel[fh.ML]-d.species.TechLevel[fh.ML])
+	if chance < 10 {
+		chance = 10
+	} else if chance > 90 {
+		chance = 90
+	}
+	if b.rng.Roll(100) > chance {
+		b.logf("      %s fires at %s and misses.\n", u, d)
+		return
+	}
+
+	damage := u.weapon
+	if d.shieldLeft >= damage {
+		d.shieldLeft -= damage
+		b.logf("      %s hits %s, but the shields hold.\n", u, d)
+		return
+	}
+	damage -= d.shieldLeft
+	d.shieldLeft = 0
+	d.damage += damage
+	b.logf("      %s hits %s for %d points of damage.\n", u, d, damage)
+
+	if d.unitType == common.NAMPLA {
+		// every two points of damage destroys a planetary defense unit
+		lost := damage / 2
+		if pds := d.nampla.ItemQuantity[fh.PD]; lost > pds {
+			lost = pds
+		}
+		d.nampla.ItemQuantity[fh.PD] -= lost
+		d.pdsLost += lost
+		fresh := newNamplaCombatant(d.species, d.nampla)
+		d.shots, d.weapon, d.shield = fresh.shots, fresh.weapon, fresh.shield
+		if d.nampla.ItemQuantity[fh.PD] == 0 {
+			b.logf("      All planetary defenses on %s have been destroyed!\n", d)
+		}
+		return
+	}
+
+	if d.damage >= d.hull {
+		d.destroyed = true
+		b.lost[d.species.ID] += d.ship.Tonnage
+		b.logf("      %s is destroyed!\n", d)
+	}
+}
+
+// checkFleetWithdrawal withdraws a species' fleet once its losses reach the WITHDRAW percentage.
+func (b *battle) checkFleetWithdrawal(units []*combatant) {
+	for _, sp := range b.species {
+		bo := b.ordersFor(sp)
+		if b.withdrew[sp.ID] || b.tonnage[sp.ID] == 0 || bo.fleetWithdrawPercentage >= 100 {
+			continue
+		} else if 100*b.lost[sp.ID]/b.tonnage[sp.ID] < bo.fleetWithdrawPercentage {
+			continue
+		}
+		b.withdrew[sp.ID] = true
+		b.logf("    SP %s's losses have reached %d%%. The fleet withdraws!\n", sp.Name, bo.fleetWithdrawPercentage)
+		for _, u := range units {
+			if u.species == sp && u.unitType == common.SHIP && u.active() {
+				b.withdraw(u)
+			}
+		}
+	}
+}
+
+// attackPlanet carries out a planet attack, bombardment, germ warfare or siege.
+func (b *battle) attackPlanet(sp *fh.SpeciesData, option engageOption) {
+	var attackers, units []*combatant
+	for _, u := range b.units {
+		if u.species == sp && u.unitType == common.SHIP && u.active() && !u.isTransport() {
+			attackers = append(attackers, u)
+		}
+	}
+	if len(attackers) == 0 {
+		return
+	}
+	var planets []*combatant
+	for _, u := range b.units {
+		if !u.active() || !b.engaged(sp, u.species) {
+			continue
+		}
+		if u.unitType == common.NAMPLA && u.nampla.PN == option.pn && !u.nampla.Hidden {
+			planets = append(planets, u)
+			units = append(units, u)
+		} else if u.unitType == common.SHIP && u.ship.PN == option.pn && (u.ship.Status == fh.ON_SURFACE || u.ship.Status == fh.IN_ORBIT) {
+			units = append(units, u)
+		}
+	}
+	if len(planets) == 0 {
+		b.t.Logf(sp, "\n  There are no enemy planets to attack at planet #%d.\n", option.pn)
+		return
+	}
+
+	b.logf("\n  SP %s attacks planet #%d:\n", sp.Name, option.pn)
+	b.fightRounds(append(units, attackers...))
+
+	for _, p := range planets {
+		if p.nampla.ItemQuantity[fh.PD] != 0 {
+			continue
+		}
+		switch option.action {
+		case common.PLANET_BOMBARDMENT:
+			b.bombard(sp, attackers, p)
+		case common.GERM_WARFARE:
+			b.germWarfare(sp, attackers, p)
+		case common.SIEGE:
+			b.besiege(sp, attackers, p)
+		}
+	}
+}
+
+// bombard destroys a share of the planet's economic base proportional to the attackers' weapons.
+func (b *battle) bombard(sp *fh.SpeciesData, attackers []*combatant, p *combatant) {
+	var firepower int
+	for _, u := range attackers {
+		if u.active() {
+			firepower += u.shots * u.weapon
+		}
+	}
+	base := p.nampla.MIBase + p.nampla.MABase
+	if firepower == 0 || base == 0 {
+		return
+	}
+	pct := (100 * firepower) / (10 * base)
+	if pct < 1 {
+		pct = 1
+	} else if pct > 100 {
+		pct = 100
+	}
+	if p.nampla.Status&fh.HOME_PLANET != 0 && p.species.HPOriginalBase == 0 {
+		p.species.HPOriginalBase = base
+	}
+	p.nampla.MIBase -= (p.nampla.MIBase * pct) / 100
+	p.nampla.MABase -= (p.nampla.MABase * pct) / 100
+	p.nampla.PopUnits -= (p.nampla.PopUnits * pct) / 100
+	p.nampla.ItemQuantity[fh.IU] -= (p.nampla.ItemQuantity[fh.IU] * pct) / 100
+	p.nampla.ItemQuantity[fh.AU] -= (p.nampla.ItemQuantity[fh.AU] * pct) / 100
+	b.logf("    SP %s bombards %s, destroying %d%% of its economic base.\n", sp.Name, p, pct)
+}
+
+// germWarfare drops germ warfare bombs carried by the attackers. The
+// first bomb to get past the defender's biology wipes out the colony.
+func (b *battle) germWarfare(sp *fh.SpeciesData, attackers []*combatant, p *combatant) {
+	chance := 50 + 2*(sp.TechLevel[fh.BI]-p.species.TechLevel[fh.BI])
+	if chance < 10 {
+		chance = 10
+	} else if chance > 90 {
+		chance = 90
+	}
+	for _, u := range attackers {
+		for u.active() && u.ship.ItemQuantity[fh.GW] > 0 {
+			u.ship.ItemQuantity[fh.GW]--
+			if b.rng.Roll(100) > chance {
+				b.logf("    A germ warfare bomb from %s is neutralized by %s.\n", u, p)
+				continue
+			}
+			if p.nampla.Status&fh.HOME_PLANET != 0 && p.species.HPOriginalBase == 0 {
+				p.species.HPOriginalBase = p.nampla.MIBase + p.nampla.MABase
+			}
+			p.nampla.MIBase, p.nampla.MABase, p.nampla.PopUnits = 0, 0, 0
+			p.nampla.ItemQuantity[fh.IU], p.nampla.ItemQuantity[fh.AU], p.nampla.ItemQuantity[fh.CU] = 0, 0, 0
+			b.logf("    A germ warfare bomb from %s wipes out the population of %s!\n", u, p)
+			return
+		}
+	}
+}
+
+// besiege sets the siege effectiveness based on the tonnage of the besieging warships.
+func (b *battle) besiege(sp *fh.SpeciesData, attackers []*combatant, p *combatant) {
+	var tonnage int
+	for _, u := range attackers {
+		if u.active() {
+			tonnage += u.ship.Tonnage
+		}
+	}
+	eff := 50 + tonnage/2
+	if eff > 99 {
+		eff = 99
+	}
+	if eff > p.nampla.SiegeEff {
+		p.nampla.SiegeEff = eff
+	}
+	b.logf("    SP %s besieges %s. Siege effectiveness is %d%%.\n", sp.Name, p, p.nampla.SiegeEff)
+}
+
+// summarize writes the results of the battle to every participant's log.
+func (b *battle) summarize() {
+	for _, sp := range b.species {
+		b.t.Logf(sp, "\n  Summary of the battle at %d %d %d:\n", b.x, b.y, b.z)
+		for _, other := range b.species {
+			var destroyed []string
+			var pdsLost int
+			for _, u := range b.units {
+				if u.species != other {
+					continue
+				} else if u.destroyed {
+					destroyed = append(destroyed, u.ship.Display())
+				}
+				pdsLost += u.pdsLost
+			}
+			sort.Strings(destroyed)
+			switch {
+			case len(destroyed) == 0 && pdsLost == 0:
+				b.t.Logf(sp, "    SP %s suffered no losses.\n", other.Name)
+			case len(destroyed) == 0:
+				b.t.Logf(sp, "    SP %s lost %d planetary defense units.\n", other.Name, pdsLost)
+			default:
+				b.t.Logf(sp, "    SP %s lost %d planetary defense units and these ships: %s.\n", other.Name, pdsLost, strings.Join(destroyed, ", "))
+			}
+		}
+	}
+}
+
+// removeDestroyedShips deletes destroyed ships and ages the survivors by the damage they took.
+func (b *battle) removeDestroyedShips() {
+	destroyed := make(map[*fh.ShipData]bool)
+	for _, u := range b.units {
+		if u.unitType != common.SHIP {
+			continue
+		} else if u.destroyed {
+			destroyed[u.ship] = true
+		} else if u.damage > 0 {
+			u.ship.Age += (50 * u.damage) / u.hull
+		}
+	}
+	if len(destroyed) == 0 {
+		return
+	}
+	for _, sp := range b.species {
+		var ships []*fh.ShipData
+		for _, ship := range sp.Ships {
+			if !destroyed[ship] {
+				ships = append(ships, ship)
+			}
+		}
+		sp.Ships = ships
+		sp.NumShips = len(ships)
+	}
+	b.t.locations = nil
+}
@@ -0,0 +1,166 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"strings"
+	"testing"
+)
+
+// newBattleTurn returns a turn with two species in the system at 10 10 10.
+// SP Raiders has two corvettes in orbit around planet #2. SP Settlers
+// has a colony on planet #2 and a corvette in orbit around it. Each
+// species gets the COMBAT orders given.
+func newBattleTurn(t *testing.T, raiders, settlers string) (*Turn, *fh.SpeciesData, *fh.SpeciesData) {
+	sp1 := &fh.SpeciesData{ID: "01", Number: 1, Name: "Raiders"}
+	sp1.TechLevel[fh.ML], sp1.TechLevel[fh.LS], sp1.TechLevel[fh.BI] = 20, 10, 30
+	sp1.Ships = []*fh.ShipData{
+		{Name: "Sword", X: 10, Y: 10, Z: 10, PN: 2, Status: fh.IN_ORBIT, Class: fh.CT, Tonnage: 2, Age: 2},
+		{Name: "Spear", X: 10, Y: 10, Z: 10, PN: 2, Status: fh.IN_ORBIT, Class: fh.CT, Tonnage: 2, Age: 8},
+	}
+	sp2 := &fh.SpeciesData{ID: "02", Number: 2, Name: "Settlers"}
+	sp2.TechLevel[fh.ML], sp2.TechLevel[fh.LS], sp2.TechLevel[fh.BI] = 10, 10, 10
+	colony := &fh.NamedPlanetData{Name: "Landing", X: 10, Y: 10, Z: 10, PN: 2, Status: fh.COLONY | fh.POPULATED, PopUnits: 50, MIBase: 100, MABase: 60}
+	colony.ItemQuantity[fh.IU], colony.ItemQuantity[fh.AU] = 20, 10
+	sp2.Namplas = []*fh.NamedPlanetData{colony}
+	sp2.Ships = []*fh.ShipData{
+		{Name: "Picket", X: 10, Y: 10, Z: 10, PN: 2, Status: fh.IN_ORBIT, Class: fh.CT, Tonnage: 2, Age: 1},
+	}
+	sp1.NumShips, sp2.NumShips, sp2.NumNamplas = 2, 1, 1
+
+	g := &fh.GalaxyData{Seed: 1, Species: map[string]*fh.SpeciesData{sp1.ID: sp1, sp2.ID: sp2}}
+	turn := &Turn{Galaxy: g, Orders: make(map[string]*orders.Orders), logs: make(map[string]*strings.Builder)}
+	for sp, text := range map[*fh.SpeciesData]string{sp1: raiders, sp2: settlers} {
+		o, err := orders.Parse(strings.NewReader("START COMBAT\n" + text + "END\n"))
+		if err != nil {
+			t.Fatalf("SP %s: %v", sp.Name, err)
+		}
+		turn.Orders[sp.ID] = o
+	}
+	return turn, sp1, sp2
+}
+
+// TestBattle pins the log of a seeded battle. The raiders win in deep
+// space and then lose both ships attacking the colony's defenses.
+func TestBattle(t *testing.T) {
+	turn, sp1, sp2 := newBattleTurn(t, "Battle 10 10 10\nAttack SP Settlers\nEngage 4 2\n", "")
+	sp2.Namplas[0].ItemQuantity[fh.PD] = 200
+	if err := turn.Combat(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+Battle at x = 10, y = 10, z = 10:
+  SP Raiders is present with 2 ships and 0 planets.
+  SP Settlers is present with 1 ships and 1 planets.
+
+  Deep space combat:
+    Round 1:
+      SP Raiders's CT Sword fires at SP Settlers's CT Picket and misses.
+      SP Raiders's CT Spear hits SP Settlers's CT Picket for 60 points of damage.
+      SP Settlers's CT Picket is destroyed!
+
+  SP Raiders attacks planet #2:
+    Round 1:
+      SP Settlers's PL Landing fires at SP Raiders's CT Sword and misses.
+      SP Settlers's PL Landing fires at SP Raiders's CT Spear and misses.
+      SP Settlers's PL Landing fires at SP Raiders's CT Spear and misses.
+      SP Raiders's CT Sword fires at SP Settlers's PL Landing and misses.
+      SP Raiders's CT Spear hits SP Settlers's PL Landing, but the shields hold.
+    Round 2:
+      SP Settlers's PL Landing hits SP Raiders's CT Spear for 113 points of damage.
+      SP Raiders's CT Spear is destroyed!
+      SP Settlers's PL Landing hits SP Raiders's CT Sword for 113 points of damage.
+      SP Raiders's CT Sword is destroyed!
+
+  Summary of the battle at 10 10 10:
+    SP Raiders lost 0 planetary defense units and these ships: CT Spear, CT Sword.
+    SP Settlers lost 0 planetary defense units and these ships: CT Picket.
+`
+	if got := turn.logs[sp2.ID].String(); got != want {
+		t.Errorf("log: got\n%s\nwant\n%s", got, want)
+	}
+	if len(sp1.Ships) != 0 || sp1.NumShips != 0 || len(sp2.Ships) != 0 || sp2.NumShips != 0 {
+		t.Errorf("ships: got %d and %d, want none", len(sp1.Ships), len(sp2.Ships))
+	}
+	if pds := sp2.Namplas[0].ItemQuantity[fh.PD]; pds != 200 {
+		t.Errorf("planetary defenses: got %d, want 200", pds)
+	}
+}
+
+// TestPlanetAttacks checks what each kind of attack does to an
+// undefended colony once its defending ship is gone.
+func TestPlanetAttacks(t *testing.T) {
+	for _, tc := range []struct {
+		option                      string
+		log                         string
+		miBase, maBase, pop, iu, au int
+		siege                       int
+	}{
+		{"5", "SP Raiders bombards SP Settlers's PL Landing, destroying 10% of its economic base.", 90, 54, 45, 18, 9, 0},
+		{"6", "A germ warfare bomb from SP Raiders's CT Sword wipes out the population of SP Settlers's PL Landing!", 0, 0, 0, 0, 0, 0},
+		{"7", "SP Raiders besieges SP Settlers's PL Landing. Siege effectiveness is 52%.", 100, 60, 50, 20, 10, 52},
+	} {
+		turn, sp1, sp2 := newBattleTurn(t, "Battle 10 10 10\nAttack SP Settlers\nEngage "+tc.option+" 2\n", "")
+		sp1.Ships[0].ItemQuantity[fh.GW] = 3
+		if err := turn.Combat(); err != nil {
+			t.Fatal(err)
+		}
+		if log := turn.logs[sp2.ID].String(); !strings.Contains(log, tc.log) {
+			t.Errorf("engage %s: log does not contain %q:\n%s", tc.option, tc.log, log)
+		}
+		nampla := sp2.Namplas[0]
+		if nampla.MIBase != tc.miBase || nampla.MABase != tc.maBase || nampla.PopUnits != tc.pop ||
+			nampla.ItemQuantity[fh.IU] != tc.iu || nampla.ItemQuantity[fh.AU] != tc.au || nampla.SiegeEff != tc.siege {
+			t.Errorf("engage %s: got mi %d, ma %d, pop %d, iu %d, au %d, siege %d", tc.option,
+				nampla.MIBase, nampla.MABase, nampla.PopUnits, nampla.ItemQuantity[fh.IU], nampla.ItemQuantity[fh.AU], nampla.SiegeEff)
+		}
+	}
+}
+
+func TestWithdrawal(t *testing.T) {
+	// ships older than the warship age leave for the haven before the fighting starts
+	turn, sp1, _ := newBattleTurn(t, "Battle 10 10 10\nAttack SP Settlers\nWithdraw 100, 5, 100\nHaven 11 12 13\n", "")
+	if err := turn.Combat(); err != nil {
+		t.Fatal(err)
+	}
+	spear := sp1.Ships[1]
+	if spear.Status != fh.JUMPED_IN_COMBAT || spear.X != 11 || spear.Y != 12 || spear.Z != 13 || spear.PN != 0 {
+		t.Errorf("age: Spear has status %d at %d %d %d #%d, want the haven", spear.Status, spear.X, spear.Y, spear.Z, spear.PN)
+	}
+	if log := turn.logs[sp1.ID].String(); !strings.Contains(log, "SP Raiders's CT Spear withdraws to 11 12 13.") {
+		t.Errorf("age: log does not report the withdrawal:\n%s", log)
+	}
+
+	// the rest of the fleet leaves once half of the tonnage is lost. The
+	// colony fires one shot a round, so it can't destroy both ships at once.
+	turn, sp1, sp2 := newBattleTurn(t, "Battle 10 10 10\nAttack SP Settlers\nWithdraw 100, 100, 50\nEngage 4 2\n", "")
+	sp2.Namplas[0].ItemQuantity[fh.PD] = 90
+	if err := turn.Combat(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sp1.Ships) != 1 || sp1.Ships[0].Status != fh.IN_DEEP_SPACE {
+		t.Errorf("fleet: want one ship to survive in deep space, got %d ships", len(sp1.Ships))
+	}
+	if log := turn.logs[sp1.ID].String(); !strings.Contains(log, "SP Raiders's losses have reached 50%. The fleet withdraws!") {
+		t.Errorf("fleet: log does not report the withdrawal:\n%s", log)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+)
+
+// Finish does the end of turn processing. Experience points become tech
+// levels, populations grow, colonial units are installed, mining makes
+// planets harder to mine, bombed home planets recover, ships age and the
+// turn number is incremented.
+//
+// The work is done on a copy of the galaxy, which replaces the galaxy
+// only if every step succeeds. A failure leaves the galaxy as it was.
+func (t *Turn) Finish() error {
+	g, err := t.Galaxy.Clone()
+	if err != nil {
+		return err
+	}
+
+	for _, star := range g.AllStars() {
+		if star == nil {
+			continue
+		}
+		for _, planet := range star.Planets {
+			planet.MiningDifficulty += planet.MDIncrease
+			planet.MDIncrease = 0
+		}
+	}
+
+	for _, sp := range speciesOf(g) {
+		advanceTech(sp)
+		for _, nampla := range sp.Namplas {
+			installUnits(nampla)
+			growPopulation(sp, nampla)
+			nampla.Hidden, nampla.Hiding = nampla.Hiding, false
+		}
+		recoverHomePlanet(sp)
+		for _, ship := range sp.Ships {
+			ageShip(ship)
+		}
+		sp.NumNamplas, sp.NumShips = len(sp.Namplas), len(sp.Ships)
+		sp.FleetCost = FleetCost(sp)
+		if err := checkSpecies(sp); err != nil {
+			return fmt.Errorf("finish: SP %s: %w", sp.Name, err)
+		}
+	}
+
+	g.TurnNumber++
+
+	// commit the changes and report the results against the new galaxy
+	old := t.Galaxy
+	t.Galaxy, t.locations = g, nil
+	for _, sp := range t.AllSpecies() {
+		was := old.Species[sp.ID]
+		for tech, level := range sp.TechLevel {
+			if level > was.TechLevel[tech] {
+				t.Logf(sp, "%s tech level rose from %d to %d.\n", fh.TechName[tech], was.TechLevel[tech], level)
+			}
+		}
+		for i, nampla := range sp.Namplas {
+			if i < len(was.Namplas) && nampla.PopUnits != was.Namplas[i].PopUnits {
+				t.Logf(sp, "Population of PL %s is now %d.\n", nampla.Name, nampla.PopUnits)
+			}
+		}
+		sp.InitTechLevel = sp.TechLevel
+	}
+	return nil
+}
+
+// advanceTech turns experience points into tech levels. Raising a tech
+// from level n to n+1 costs n squared points, or half that if the species
+// has already been taught the higher level.
+func advanceTech(sp *fh.SpeciesData) {
+	for tech := range sp.TechLevel {
+		for {
+			level := sp.TechLevel[tech]
+			cost := level * level
+			if cost < 1 {
+				cost = 1
+			}
+			if sp.TechKnowledge[tech] > level {
+				cost = (cost + 1) / 2
+			}
+			if sp.TechEps[tech] < cost {
+				break
+			}
+			sp.TechEps[tech] -= cost
+			sp.TechLevel[tech]++
+		}
+		if sp.TechKnowledge[tech] < sp.TechLevel[tech] {
+			sp.TechKnowledge[tech] = sp.TechLevel[tech]
+		}
+	}
+}
+
+// installUnits adds the units installed this turn to the planet's
+// mining and manufacturing bases. The colonists running them join the
+// population.
+func installUnits(nampla *fh.NamedPlanetData) {
+	if nampla.IUsToInstall == 0 && nampla.AUsToInstall == 0 {
+		return
+	}
+	nampla.MIBase += nampla.IUsToInstall
+	nampla.MABase += nampla.AUsToInstall
+	nampla.PopUnits += nampla.IUsToInstall + nampla.AUsToInstall
+	nampla.IUsToInstall, nampla.AUsToInstall = 0, 0
+	nampla.Status |= fh.POPULATED
+}
+
+// growPopulation grows the available population of a populated planet
+// by two percent plus one percent for every ten levels of biology. The
+// home planet can't grow past its limit; colonies have no limit.
+func growPopulation(sp *fh.SpeciesData, nampla *fh.NamedPlanetData) {
+	if nampla.Status&fh.POPULATED == 0 || nampla.Status&fh.DISBANDED_COLONY != 0 {
+		return
+	}
+	growth := (nampla.PopUnits * (2 + sp.TechLevel[fh.BI]/10)) / 100
+	nampla.PopUnits += growth
+	if nampla.Status&fh.HOME_PLANET != 0 && nampla.PopUnits > fh.HP_AVAILABLE_POP {
+		nampla.PopUnits = fh.HP_AVAILABLE_POP
+	}
+}
+
+// recoverHomePlanet rebuilds a bombed home planet by five percent of its
+// original economic base each turn until it is back to that base.
+func recoverHomePlanet(sp *fh.SpeciesData) {
+	home := sp.HomeNampla
+	if sp.HPOriginalBase == 0 || home == nil {
+		return
+	}
+	current := home.MIBase + home.MABase
+	if current >= sp.HPOriginalBase {
+		sp.HPOriginalBase = 0
+		return
+	}
+	increase := sp.HPOriginalBase / 20
+	if increase < 1 {
+		increase = 1
+	}
+	if increase > sp.HPOriginalBase-current {
+		increase = sp.HPOriginalBase - current
+	}
+	mi := increase / 2
+	if current > 0 {
+		mi = (increase * home.MIBase) / current
+	}
+	home.MIBase += mi
+	home.MABase += increase - mi
+	if home.MIBase+home.MABase >= sp.HPOriginalBase {
+		sp.HPOriginalBase = 0
+	}
+}
+
+// ageShip ages a ship by one turn and clears the movement flags.
+func ageShip(ship *fh.ShipData) {
+	if ship.Status == fh.UNDER_CONSTRUCTION {
+		return
+	}
+	ship.Age++
+	ship.JustJumped = false
+	if ship.Status == fh.JUMPED_IN_COMBAT || ship.Status == fh.FORCED_JUMP {
+		if ship.PN == 0 {
+			ship.Status = fh.IN_DEEP_SPACE
+		} else {
+			ship.Status = fh.IN_ORBIT
+		}
+	}
+}
+
+// checkSpecies returns an error if the end of turn processing left a
+// species in an impossible state.
+func checkSpecies(sp *fh.SpeciesData) error {
+	if sp.EconUnits < 0 {
+		return fmt.Errorf("negative economic units (%d)", sp.EconUnits)
+	}
+	for _, nampla := range sp.Namplas {
+		if nampla.PopUnits < 0 {
+			return fmt.Errorf("PL %s: negative population (%d)", nampla.Name, nampla.PopUnits)
+		}
+		for item, quantity := range nampla.ItemQuantity {
+			if quantity < 0 {
+				return fmt.Errorf("PL %s: negative quantity of %s (%d)", nampla.Name, fh.Item(item).Abbr(), quantity)
+			}
+		}
+	}
+	for _, ship := range sp.Ships {
+		for item, quantity := range ship.ItemQuantity {
+			if quantity < 0 {
+				return fmt.Errorf("%s: negative quantity of %s (%d)", ship.Display(), fh.Item(item).Abbr(), quantity)
+			}
+		}
+	}
+	return nil
+}
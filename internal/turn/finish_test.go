@@ -0,0 +1,74 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"strings"
+	"testing"
+)
+
+// newFinishTurn returns a turn for a species with a home planet and a
+// populated colony in the system at 1 2 3.
+func newFinishTurn(homePop, colonyPop int) *Turn {
+	home := &fh.NamedPlanetData{Name: "Home", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.HOME_PLANET | fh.POPULATED, PopUnits: homePop}
+	colony := &fh.NamedPlanetData{Name: "Colony", X: 1, Y: 2, Z: 3, PN: 2, Status: fh.COLONY | fh.POPULATED, PopUnits: colonyPop}
+	sp := &fh.SpeciesData{ID: "01", Number: 1, Name: "Test", X: 1, Y: 2, Z: 3, PN: 1, RequiredGas: fh.O2, Namplas: []*fh.NamedPlanetData{home, colony}}
+	sp.TechLevel[fh.BI] = 20
+	star := &fh.StarData{ID: fh.XYZToID(1, 2, 3), X: 1, Y: 2, Z: 3, Type: fh.MAIN_SEQUENCE, Color: fh.YELLOW, NumPlanets: 2, Planets: []*fh.PlanetData{{}, {}}}
+	g := &fh.GalaxyData{TurnNumber: 3, Species: map[string]*fh.SpeciesData{sp.ID: sp}, Stars: map[string]*fh.StarData{star.ID: star}}
+	return &Turn{Galaxy: g, Orders: make(map[string]*orders.Orders), logs: make(map[string]*strings.Builder)}
+}
+
+func TestFinishGrowsPopulation(t *testing.T) {
+	for _, tc := range []struct {
+		home, colony int
+		wantHome     int
+		wantColony   int
+	}{
+		{1000, 100, 1040, 104}, // two percent plus two for biology 20
+		{fh.HP_AVAILABLE_POP - 10, 1000, fh.HP_AVAILABLE_POP, 1040}, // only the home planet is capped
+	} {
+		turn := newFinishTurn(tc.home, tc.colony)
+		if err := turn.Finish(); err != nil {
+			t.Fatal(err)
+		}
+		sp := turn.Galaxy.Species["01"]
+		if home, colony := sp.Namplas[0].PopUnits, sp.Namplas[1].PopUnits; home != tc.wantHome || colony != tc.wantColony {
+			t.Errorf("population %d and %d: got %d and %d, want %d and %d", tc.home, tc.colony, home, colony, tc.wantHome, tc.wantColony)
+		}
+		if turn.Galaxy.TurnNumber != 4 {
+			t.Errorf("turn number: got %d, want 4", turn.Galaxy.TurnNumber)
+		}
+	}
+}
+
+func TestFinishRejectsInvalidSpecies(t *testing.T) {
+	turn := newFinishTurn(1000, 100)
+	before := turn.Galaxy
+	before.Species["01"].Namplas[1].ItemQuantity[fh.CU] = -1
+	err := turn.Finish()
+	if err == nil || err.Error() != "finish: SP Test: PL Colony: negative quantity of CU (-1)" {
+		t.Errorf("finish: got %v, want the negative quantity to be reported", err)
+	}
+	if turn.Galaxy != before || before.TurnNumber != 3 || before.Species["01"].Namplas[1].PopUnits != 100 {
+		t.Errorf("finish: the galaxy was changed")
+	}
+}
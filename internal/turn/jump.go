@@ -0,0 +1,201 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+)
+
+// Jump runs the JUMPS section of every species' orders, moving ships
+// with JUMP, MOVE and WORMHOLE orders. Jumps may end in a mishap that
+// destroys the ship or sends it to the wrong place.
+func (t *Turn) Jump() error {
+	rng := t.Galaxy.Stream(fh.JUMP_STREAM)
+	for _, sp := range t.AllSpecies() {
+		// the flag only lasts until the turn after the ship arrives
+		for _, ship := range sp.Ships {
+			ship.ArrivedViaWormhole = false
+		}
+
+		destroyed := make(map[*fh.ShipData]bool)
+		for _, cmd := range t.Commands(sp, orders.JUMPS) {
+			ship := sp.GetShipByName(cmd.Args[0].Name)
+			if ship == nil {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such ship.\n", cmd.Line, cmd)
+				continue
+			} else if destroyed[ship] {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: ship was destroyed.\n", cmd.Line, cmd)
+				continue
+			} else if ship.Status == fh.UNDER_CONSTRUCTION {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: ship is still under construction.\n", cmd.Line, cmd)
+				continue
+			} else if ship.JustJumped || ship.Status == fh.JUMPED_IN_COMBAT || ship.Status == fh.FORCED_JUMP {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: ship has already jumped this turn.\n", cmd.Line, cmd)
+				continue
+			}
+
+			switch cmd.Code {
+			case fh.JUMP:
+				if t.jump(rng, sp, ship, cmd) {
+					destroyed[ship] = true
+				}
+			case fh.MOVE:
+				t.move(sp, ship, cmd)
+			case fh.WORMHOLE:
+				t.wormhole(sp, ship, cmd)
+			case fh.PJUMP:
+				t.Logf(sp, "!!! Order ignored on line %d: %q: jump portals are not supported yet.\n", cmd.Line, cmd)
+			}
+		}
+
+		if len(destroyed) != 0 {
+			var ships []*fh.ShipData
+			for _, ship := range sp.Ships {
+				if !destroyed[ship] {
+					ships = append(ships, ship)
+				}
+			}
+			sp.Ships, sp.NumShips = ships, len(ships)
+		}
+	}
+	t.locations = nil
+	return nil
+}
+
+// jump carries out a JUMP order. It returns true if the ship was destroyed.
+func (t *Turn) jump(rng *fh.RNG, sp *fh.SpeciesData, ship *fh.ShipData, cmd *orders.Command) bool {
+	if ship.Type != fh.FTL {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: only FTL ships can jump.\n", cmd.Line, cmd)
+		return false
+	}
+
+	x, y, z, pn, ok := t.destination(sp, cmd.Args[1])
+	if !ok {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+		return false
+	} else if x == ship.X && y == ship.Y && z == ship.Z {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: ship is already at the destination.\n", cmd.Line, cmd)
+		return false
+	}
+
+	mishapChance := fh.MishapChance(fh.DistanceSquared(ship.X, ship.Y, ship.Z, x, y, z), sp.TechLevel[fh.GV], ship.Age)
+	if rng.Roll(10000) <= mishapChance {
+		if ship.ItemQuantity[fh.FS] > 0 {
+			// a fail-safe jump unit aborts the jump before anything goes wrong
+			ship.ItemQuantity[fh.FS]--
+			ship.JustJumped = true
+			t.Logf(sp, "%s had a mishap, but a fail-safe jump unit returned it safely to %d %d %d.\n", ship.Display(), ship.X, ship.Y, ship.Z)
+			return false
+		}
+		mishap := &fh.Transaction{Type: fh.SHIP_MISHAP, Donor: sp.ID, Recipient: sp.ID, Name1: ship.Display()}
+		if rng.Roll(100) <= 50 {
+			mishap.X, mishap.Y, mishap.Z = x, y, z
+			t.Record(mishap)
+			return true
+		}
+		// misjump to somewhere near the destination
+		x, y, z, pn = t.misjump(rng, x), t.misjump(rng, y), t.misjump(rng, z), 0
+		mishap.Value, mishap.X, mishap.Y, mishap.Z = 1, x, y, z
+		t.Record(mishap)
+	} else {
+		t.Logf(sp, "%s jumped to %d %d %d.\n", ship.Display(), x, y, z)
+	}
+
+	t.arrive(sp, ship, x, y, z, pn)
+	ship.JustJumped = true
+	return false
+}
+
+// move carries out a MOVE order. Any ship may move one parsec in one direction.
+func (t *Turn) move(sp *fh.SpeciesData, ship *fh.ShipData, cmd *orders.Command) {
+	loc := cmd.Args[1]
+	if ship.Type == fh.STARBASE && ship.Status != fh.IN_DEEP_SPACE && ship.Status != fh.IN_ORBIT {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: ship can't move from its current location.\n", cmd.Line, cmd)
+		return
+	} else if fh.DistanceSquared(ship.X, ship.Y, ship.Z, loc.X, loc.Y, loc.Z) != 1 {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: ships may only move one parsec.\n", cmd.Line, cmd)
+		return
+	}
+	t.arrive(sp, ship, loc.X, loc.Y, loc.Z, 0)
+	ship.JustJumped = true
+	t.Logf(sp, "%s moved to %d %d %d.\n", ship.Display(), loc.X, loc.Y, loc.Z)
+}
+
+// wormhole carries out a WORMHOLE order. Wormholes never cause mishaps.
+func (t *Turn) wormhole(sp *fh.SpeciesData, ship *fh.ShipData, cmd *orders.Command) {
+	star := t.Galaxy.GetStarAt(ship.X, ship.Y, ship.Z)
+	if star == nil || !star.WormHere {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: there is no wormhole at %d %d %d.\n", cmd.Line, cmd, ship.X, ship.Y, ship.Z)
+		return
+	}
+	x, y, z, pn := star.WormX, star.WormY, star.WormZ, 0
+	if len(cmd.Args) > 1 {
+		nampla := sp.GetNamplaByName(cmd.Args[1].Name)
+		if nampla == nil || nampla.X != x || nampla.Y != y || nampla.Z != z {
+			t.Logf(sp, "!!! Order ignored on line %d: %q: planet is not at the other end of the wormhole.\n", cmd.Line, cmd)
+			return
+		}
+		pn = nampla.PN
+	}
+	t.arrive(sp, ship, x, y, z, pn)
+	ship.JustJumped = true
+	ship.ArrivedViaWormhole = true
+	t.Logf(sp, "%s travelled through the wormhole to %d %d %d.\n", ship.Display(), x, y, z)
+}
+
+// destination returns the location named by a jump argument.
+func (t *Turn) destination(sp *fh.SpeciesData, arg *orders.Arg) (x, y, z, pn int, ok bool) {
+	if arg.Kind == orders.PLANET_ID {
+		nampla := sp.GetNamplaByName(arg.Name)
+		if nampla == nil {
+			return 0, 0, 0, 0, false
+		}
+		return nampla.X, nampla.Y, nampla.Z, nampla.PN, true
+	}
+	return arg.X, arg.Y, arg.Z, arg.PN, true
+}
+
+// misjump returns a coordinate up to five parsecs away, staying inside the galaxy.
+func (t *Turn) misjump(rng *fh.RNG, coord int) int {
+	coord += rng.Roll(11) - 6
+	if coord < 0 {
+		coord = 0
+	} else if max := 2*t.Galaxy.Radius - 1; coord > max {
+		coord = max
+	}
+	return coord
+}
+
+// arrive puts the ship at its new location and marks the star system as visited.
+func (t *Turn) arrive(sp *fh.SpeciesData, ship *fh.ShipData, x, y, z, pn int) {
+	ship.X, ship.Y, ship.Z, ship.PN = x, y, z, pn
+	if pn == 0 {
+		ship.Status = fh.IN_DEEP_SPACE
+	} else {
+		ship.Status = fh.IN_ORBIT
+	}
+	if star := t.Galaxy.GetStarAt(x, y, z); star != nil {
+		if star.VisitedBy == nil {
+			star.VisitedBy = make(map[string]bool)
+		}
+		star.VisitedBy[sp.ID] = true
+	}
+	t.eventMessages(sp, t.Galaxy.EventMessagesAt(x, y, z, pn), x, y, z)
+}
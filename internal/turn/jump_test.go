@@ -0,0 +1,146 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"strings"
+	"testing"
+)
+
+// newJumpTurn returns a turn for a species with a corvette at its home
+// planet in the system at 1 2 3. The system at 4 2 3 has a colony and a
+// wormhole to the system at 15 15 15.
+func newJumpTurn(t *testing.T, text string) (*Turn, *fh.SpeciesData, *fh.ShipData) {
+	home := &fh.NamedPlanetData{Name: "Home", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.HOME_PLANET}
+	colony := &fh.NamedPlanetData{Name: "Colony", X: 4, Y: 2, Z: 3, PN: 2, Status: fh.COLONY}
+	ship := &fh.ShipData{Name: "Scout", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.IN_ORBIT, Type: fh.FTL, Class: fh.CT, Tonnage: 2}
+	sp := &fh.SpeciesData{ID: "01", Number: 1, Name: "Test", Namplas: []*fh.NamedPlanetData{home, colony}, Ships: []*fh.ShipData{ship}, NumShips: 1}
+	sp.TechLevel[fh.GV] = 100
+
+	g := &fh.GalaxyData{Seed: 1, Radius: 10, Species: map[string]*fh.SpeciesData{sp.ID: sp}, Stars: make(map[string]*fh.StarData)}
+	for _, star := range []*fh.StarData{
+		{X: 1, Y: 2, Z: 3},
+		{X: 4, Y: 2, Z: 3, WormHere: true, WormX: 15, WormY: 15, WormZ: 15},
+		{X: 15, Y: 15, Z: 15, WormHere: true, WormX: 4, WormY: 2, WormZ: 3},
+	} {
+		g.Stars[fh.XYZToID(star.X, star.Y, star.Z)] = star
+	}
+
+	turn := &Turn{Galaxy: g, Orders: make(map[string]*orders.Orders), logs: make(map[string]*strings.Builder)}
+	o, err := orders.Parse(strings.NewReader("START JUMPS\n" + text + "END\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	turn.Orders[sp.ID] = o
+	return turn, sp, ship
+}
+
+func TestJump(t *testing.T) {
+	for _, tc := range []struct {
+		orders  string
+		x, y, z int
+		pn      int
+		status  fh.ShipStatus
+		log     string
+	}{
+		{"Jump CT Scout, PL Colony\n", 4, 2, 3, 2, fh.IN_ORBIT, "CT Scout jumped to 4 2 3.\n"},
+		{"Jump CT Scout, 2 2 3\n", 2, 2, 3, 0, fh.IN_DEEP_SPACE, "CT Scout jumped to 2 2 3.\n"},
+		{"Move CT Scout, 1 2 4\n", 1, 2, 4, 0, fh.IN_DEEP_SPACE, "CT Scout moved to 1 2 4.\n"},
+		{"Move CT Scout, 1 4 3\n", 1, 2, 3, 1, fh.IN_ORBIT, "ships may only move one parsec"},
+		{"Jump CT Scout, PL Colony\nMove CT Scout, 4 2 4\n", 4, 2, 3, 2, fh.IN_ORBIT, "ship has already jumped this turn"},
+		{"Jump CT Scout, PL Nowhere\n", 1, 2, 3, 1, fh.IN_ORBIT, "you have no such planet"},
+		{"Wormhole CT Scout\n", 1, 2, 3, 1, fh.IN_ORBIT, "there is no wormhole at 1 2 3"},
+	} {
+		turn, sp, ship := newJumpTurn(t, tc.orders)
+		if err := turn.Jump(); err != nil {
+			t.Fatal(err)
+		}
+		if ship.X != tc.x || ship.Y != tc.y || ship.Z != tc.z || ship.PN != tc.pn || ship.Status != tc.status {
+			t.Errorf("%q: ship at %d %d %d #%d, status %d, want %d %d %d #%d, status %d", tc.orders,
+				ship.X, ship.Y, ship.Z, ship.PN, ship.Status, tc.x, tc.y, tc.z, tc.pn, tc.status)
+		}
+		if log := turn.logs[sp.ID].String(); !strings.Contains(log, tc.log) {
+			t.Errorf("%q: log %q does not contain %q", tc.orders, log, tc.log)
+		}
+	}
+
+	// a jump marks the destination as visited and the wormhole takes the ship back out
+	turn, sp, ship := newJumpTurn(t, "Jump CT Scout, PL Colony\n")
+	if err := turn.Jump(); err != nil {
+		t.Fatal(err)
+	} else if !turn.Galaxy.GetStarAt(4, 2, 3).VisitedBy[sp.ID] {
+		t.Errorf("jump: destination was not marked as visited")
+	}
+	ship.JustJumped = false
+	turn.Orders[sp.ID], _ = orders.Parse(strings.NewReader("START JUMPS\nWormhole CT Scout\nEND\n"))
+	if err := turn.Jump(); err != nil {
+		t.Fatal(err)
+	} else if ship.X != 15 || ship.Y != 15 || ship.Z != 15 || !ship.ArrivedViaWormhole {
+		t.Errorf("wormhole: ship at %d %d %d, arrived via wormhole %v, want 15 15 15, true", ship.X, ship.Y, ship.Z, ship.ArrivedViaWormhole)
+	}
+}
+
+// TestJumpMishap uses an old ship with no gravitics, so that every jump
+// has a mishap.
+func TestJumpMishap(t *testing.T) {
+	var destroyed, misjumped int
+	for seed := uint64(1); seed <= 20; seed++ {
+		turn, sp, ship := newJumpTurn(t, "Jump CT Scout, 12 12 12\n")
+		turn.Galaxy.Seed = seed
+		sp.TechLevel[fh.GV], ship.Age = 0, 50
+		if err := turn.Jump(); err != nil {
+			t.Fatal(err)
+		}
+		mishaps := turn.Galaxy.Ledger.OfType(fh.SHIP_MISHAP)
+		if len(mishaps) != 1 {
+			t.Errorf("seed %d: got %d mishaps, want 1", seed, len(mishaps))
+			continue
+		}
+		switch tr := mishaps[0]; {
+		case tr.Value == 0:
+			destroyed++
+			if len(sp.Ships) != 0 || sp.NumShips != 0 {
+				t.Errorf("seed %d: destroyed ship is still in the fleet", seed)
+			}
+		case tr.X == ship.X && tr.Y == ship.Y && tr.Z == ship.Z:
+			misjumped++
+			for _, coord := range []int{ship.X, ship.Y, ship.Z} {
+				if coord < 7 || coord > 17 {
+					t.Errorf("seed %d: misjumped to %d %d %d, too far from 12 12 12", seed, ship.X, ship.Y, ship.Z)
+				}
+			}
+		default:
+			t.Errorf("seed %d: ship is at %d %d %d, but the mishap says %d %d %d", seed, ship.X, ship.Y, ship.Z, tr.X, tr.Y, tr.Z)
+		}
+	}
+	if destroyed == 0 || misjumped == 0 {
+		t.Errorf("got %d ships destroyed and %d misjumped, want some of each", destroyed, misjumped)
+	}
+
+	// a fail-safe jump unit keeps the ship where it is
+	turn, sp, ship := newJumpTurn(t, "Jump CT Scout, 12 12 12\n")
+	sp.TechLevel[fh.GV], ship.Age, ship.ItemQuantity[fh.FS] = 0, 50, 1
+	if err := turn.Jump(); err != nil {
+		t.Fatal(err)
+	} else if ship.X != 1 || ship.Y != 2 || ship.Z != 3 || ship.ItemQuantity[fh.FS] != 0 || len(sp.Ships) != 1 {
+		t.Errorf("fail-safe: ship at %d %d %d with %d fail-safe units", ship.X, ship.Y, ship.Z, ship.ItemQuantity[fh.FS])
+	}
+}
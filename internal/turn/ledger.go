@@ -0,0 +1,86 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"strings"
+)
+
+// Record adds a transaction to the ledger for the current turn. The
+// ledger is saved with the galaxy, so later phases and the reports can
+// find it.
+func (t *Turn) Record(tr *fh.Transaction) {
+	tr.Turn = t.Galaxy.TurnNumber
+	t.Galaxy.Ledger.Add(tr)
+}
+
+// speciesName returns the name of a species given its id.
+func (t *Turn) speciesName(id string) string {
+	if sp := t.Galaxy.GetSpeciesByID(id); sp != nil {
+		return sp.Name
+	}
+	return "#" + id
+}
+
+// notices returns the report lines for the transactions received by a
+// species during the turn.
+func (t *Turn) notices(sp *fh.SpeciesData) []string {
+	var lines []string
+	for _, tr := range t.Galaxy.Ledger.For(sp.ID) {
+		lines = append(lines, t.describe(tr))
+	}
+	return lines
+}
+
+// describe returns the text that tells the recipient about a transaction.
+func (t *Turn) describe(tr *fh.Transaction) string {
+	donor := t.speciesName(tr.Donor)
+	switch tr.Type {
+	case fh.EU_TRANSFER:
+		return fmt.Sprintf("SP %s transferred %d economic units to you.", donor, tr.Value)
+	case fh.SIEGE_EU_TRANSFER:
+		return fmt.Sprintf("Your siege of PL %s took %d economic units from SP %s.", tr.Name1, tr.Value, donor)
+	case fh.LOOTING_EU_TRANSFER:
+		return fmt.Sprintf("You looted %d economic units from SP %s.", tr.Value, donor)
+	case fh.TECH_TRANSFER:
+		return fmt.Sprintf("SP %s taught you %s up to level %d.", donor, fh.TechName[tr.Number1], tr.Value)
+	case fh.KNOWLEDGE_TRANSFER:
+		return fmt.Sprintf("SP %s shared their knowledge of %s up to level %d.", donor, fh.TechName[tr.Number1], tr.Value)
+	case fh.MESSAGE_TO_SPECIES:
+		m := t.Galaxy.GetMessage(tr.Number1)
+		if m == nil {
+			return fmt.Sprintf("You received a message from SP %s, but it was lost.", donor)
+		}
+		return fmt.Sprintf("You received the following message from SP %s:\n\n%s", donor, strings.Join(m.Text, "\n"))
+	case fh.BESIEGE_PLANET:
+		return fmt.Sprintf("SP %s is besieging your PL %s.", donor, tr.Name1)
+	case fh.SHIP_MISHAP:
+		owner := ""
+		if tr.Donor != tr.Recipient {
+			owner = " of SP " + donor
+		}
+		if tr.Value == 0 {
+			return fmt.Sprintf("%s%s had a mishap while jumping to %d %d %d and self-destructed!", tr.Name1, owner, tr.X, tr.Y, tr.Z)
+		}
+		return fmt.Sprintf("%s%s had a mishap while jumping and misjumped to %d %d %d!", tr.Name1, owner, tr.X, tr.Y, tr.Z)
+	}
+	return fmt.Sprintf("Transaction %s with SP %s at %d %d %d.", tr.Type, donor, tr.X, tr.Y, tr.Z)
+}
@@ -0,0 +1,34 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+// Locations builds the locations index and updates the contacts between
+// species. Two species make contact when they have ships or named
+// planets in the same star system. It is the first phase of the turn,
+// so it also reports the orders that couldn't be parsed and clears the
+// ledger of the transactions from the last turn.
+func (t *Turn) Locations() error {
+	t.logParseErrors()
+	t.Galaxy.Ledger.Clear()
+	t.locations = nil
+	for _, pair := range t.LocationIndex().UpdateContacts() {
+		t.Logf(pair[0], "You have made contact with SP %s.\n", pair[1].Name)
+	}
+	return nil
+}
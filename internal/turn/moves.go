@@ -0,0 +1,497 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"strings"
+)
+
+// PreDeparture runs the PRE-DEPARTURE section of every species' orders.
+func (t *Turn) PreDeparture() error {
+	return t.moves(orders.PRE_DEPARTURE)
+}
+
+// PostArrival runs the POST-ARRIVAL section of every species' orders.
+// It is the same as the pre-departure phase, except that ships have
+// reached their destinations and can scan their new surroundings.
+func (t *Turn) PostArrival() error {
+	return t.moves(orders.POST_ARRIVAL)
+}
+
+// moves runs the orders given before or after ships move.
+func (t *Turn) moves(section orders.Section) error {
+	for _, sp := range t.AllSpecies() {
+		for _, cmd := range t.Commands(sp, section) {
+			switch cmd.Code {
+			case fh.ALLY, fh.ENEMY, fh.NEUTRAL:
+				t.diplomacy(sp, cmd)
+			case fh.AUTO:
+				sp.AutoOrders = true
+			case fh.DEEP, fh.LAND, fh.ORBIT:
+				t.setStatus(sp, cmd)
+			case fh.DISBAND:
+				t.disband(sp, cmd)
+			case fh.HIDE:
+				if nampla := sp.GetNamplaByName(cmd.Args[0].Name); nampla != nil {
+					nampla.Hiding = true
+				} else {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+				}
+			case fh.INSTALL:
+				t.install(sp, cmd)
+			case fh.MESSAGE:
+				t.message(sp, cmd)
+			case fh.NAME:
+				t.name(sp, cmd)
+			case fh.SCAN:
+				t.scan(sp, cmd)
+			case fh.TELESCOPE:
+				t.telescope(sp, cmd)
+			case fh.TERRAFORM:
+				t.terraform(sp, cmd)
+			case fh.TRANSFER:
+				t.transfer(sp, cmd)
+			case fh.UNLOAD:
+				t.unload(sp, cmd)
+			default:
+				t.Logf(sp, "!!! Order ignored on line %d: %q: %s is not supported yet.\n", cmd.Line, cmd, fh.CommandName(cmd.Code))
+			}
+		}
+	}
+	t.locations = nil
+	return nil
+}
+
+// diplomacy changes how a species treats another species, or every
+// other species if the species number is zero.
+func (t *Turn) diplomacy(sp *fh.SpeciesData, cmd *orders.Command) {
+	relation := fh.UNALIGNED
+	if cmd.Code == fh.ALLY {
+		relation = fh.ALLIED
+	} else if cmd.Code == fh.ENEMY {
+		relation = fh.HOSTILE
+	}
+
+	if arg := cmd.Args[0]; arg.Kind == orders.NUMBER && arg.Number == 0 {
+		// every species, but only the ones that have been met can be allies
+		for _, other := range t.AllSpecies() {
+			if other != sp && (relation != fh.ALLIED || sp.HasMet(other)) {
+				sp.SetRelation(other, relation)
+			}
+		}
+		return
+	}
+	other := t.getSpecies(cmd.Args[0])
+	if other == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: there is no such species.\n", cmd.Line, cmd)
+		return
+	} else if other == sp {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you can't change your relations with yourself.\n", cmd.Line, cmd)
+		return
+	} else if err := sp.SetRelation(other, relation); err != nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have not made contact with this species.\n", cmd.Line, cmd)
+		return
+	}
+}
+
+// message sends the text of a MESSAGE order to a species that has been
+// met. The message is archived and delivered in the recipient's report.
+func (t *Turn) message(sp *fh.SpeciesData, cmd *orders.Command) {
+	other := t.getSpecies(cmd.Args[0])
+	if other == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: there is no such species.\n", cmd.Line, cmd)
+		return
+	} else if other == sp {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you can't send a message to yourself.\n", cmd.Line, cmd)
+		return
+	} else if !sp.HasMet(other) {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have not made contact with SP %s, so the message can't be delivered.\n", cmd.Line, cmd, other.Name)
+		return
+	}
+	id := t.Galaxy.AddMessage(&fh.Message{Turn: t.Galaxy.TurnNumber, From: sp.ID, To: other.ID, Text: cmd.Text})
+	t.Record(&fh.Transaction{Type: fh.MESSAGE_TO_SPECIES, Donor: sp.ID, Recipient: other.ID, Number1: id})
+	t.Logf(sp, "A message was sent to SP %s.\n", other.Name)
+}
+
+// setStatus carries out DEEP, LAND and ORBIT orders.
+func (t *Turn) setStatus(sp *fh.SpeciesData, cmd *orders.Command) {
+	ship := sp.GetShipByName(cmd.Args[0].Name)
+	if ship == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such ship.\n", cmd.Line, cmd)
+		return
+	} else if ship.Status == fh.UNDER_CONSTRUCTION {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: ship is still under construction.\n", cmd.Line, cmd)
+		return
+	}
+
+	if cmd.Code == fh.DEEP {
+		if ship.Status == fh.ON_SURFACE && ship.Type == fh.SUB_LIGHT {
+			t.Logf(sp, "!!! Order ignored on line %d: %q: sub-light ships can't lift off without help.\n", cmd.Line, cmd)
+			return
+		}
+		ship.PN, ship.Status = 0, fh.IN_DEEP_SPACE
+		return
+	}
+
+	pn := ship.PN
+	if len(cmd.Args) > 1 {
+		if arg := cmd.Args[1]; arg.Kind == orders.NUMBER {
+			pn = arg.Number
+		} else if nampla := sp.GetNamplaByName(arg.Name); nampla == nil {
+			t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+			return
+		} else if nampla.X != ship.X || nampla.Y != ship.Y || nampla.Z != ship.Z {
+			t.Logf(sp, "!!! Order ignored on line %d: %q: planet is not in the same star system as the ship.\n", cmd.Line, cmd)
+			return
+		} else {
+			pn = nampla.PN
+		}
+	}
+	if t.Galaxy.GetPlanet(ship.X, ship.Y, ship.Z, pn) == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: there is no planet %d at %d %d %d.\n", cmd.Line, cmd, pn, ship.X, ship.Y, ship.Z)
+		return
+	}
+
+	if cmd.Code == fh.LAND {
+		if ship.Type == fh.STARBASE {
+			t.Logf(sp, "!!! Order ignored on line %d: %q: starbases can't land.\n", cmd.Line, cmd)
+			return
+		}
+		ship.PN, ship.Status = pn, fh.ON_SURFACE
+	} else {
+		ship.PN, ship.Status = pn, fh.IN_ORBIT
+	}
+}
+
+// disband abandons a colony. The planet keeps its name but loses its
+// population, bases and inventory.
+func (t *Turn) disband(sp *fh.SpeciesData, cmd *orders.Command) {
+	nampla := sp.GetNamplaByName(cmd.Args[0].Name)
+	if nampla == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+		return
+	} else if nampla.Status&fh.HOME_PLANET != 0 {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you can't disband your home planet.\n", cmd.Line, cmd)
+		return
+	}
+	nampla.Status = fh.DISBANDED_COLONY
+	nampla.MIBase, nampla.MABase, nampla.PopUnits, nampla.Shipyards = 0, 0, 0, 0
+	nampla.IUsToInstall, nampla.AUsToInstall, nampla.AutoIUs, nampla.AutoAUs = 0, 0, 0, 0
+	nampla.ItemQuantity = [fh.MAX_ITEMS]int{}
+	t.Logf(sp, "PL %s was disbanded.\n", nampla.Name)
+}
+
+// install sets up mining and manufacturing units on a planet. Each unit
+// needs one colonist unit to run it. The units are installed at the end
+// of the turn, when the colonists join the planet's population.
+func (t *Turn) install(sp *fh.SpeciesData, cmd *orders.Command) {
+	amount, items := 0, []fh.Item{fh.IU, fh.AU}
+	var nampla *fh.NamedPlanetData
+	for _, arg := range cmd.Args {
+		switch arg.Kind {
+		case orders.NUMBER:
+			amount = arg.Number
+		case orders.ITEM_CLASS:
+			items = []fh.Item{fh.Item(arg.Code)}
+		case orders.PLANET_ID:
+			nampla = sp.GetNamplaByName(arg.Name)
+		}
+	}
+	if nampla == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+		return
+	} else if items[0] != fh.IU && items[0] != fh.AU {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: only IUs and AUs can be installed.\n", cmd.Line, cmd)
+		return
+	}
+
+	for _, item := range items {
+		n := nampla.ItemQuantity[item]
+		if amount != 0 && amount < n {
+			n = amount
+		}
+		if n > nampla.ItemQuantity[fh.CU] {
+			n = nampla.ItemQuantity[fh.CU]
+		}
+		if n <= 0 {
+			continue
+		}
+		nampla.ItemQuantity[item] -= n
+		nampla.ItemQuantity[fh.CU] -= n
+		if item == fh.IU {
+			nampla.IUsToInstall += n
+		} else {
+			nampla.AUsToInstall += n
+		}
+		if nampla.Status&fh.HOME_PLANET == 0 {
+			nampla.Status |= fh.COLONY
+		}
+		t.Logf(sp, "%d %ss will be installed on PL %s.\n", n, item.Abbr(), nampla.Name)
+	}
+}
+
+// name gives a name to a planet so that the species can colonize it.
+// The species must have a ship or colony in the star system.
+func (t *Turn) name(sp *fh.SpeciesData, cmd *orders.Command) {
+	loc, name := cmd.Args[0], cmd.Args[1].Name
+	planet := t.Galaxy.GetPlanet(loc.X, loc.Y, loc.Z, loc.PN)
+	if planet == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: there is no such planet.\n", cmd.Line, cmd)
+		return
+	} else if sp.GetNamplaByName(name) != nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you already have a planet with that name.\n", cmd.Line, cmd)
+		return
+	}
+	present := false
+	for _, nampla := range sp.Namplas {
+		if nampla.X == loc.X && nampla.Y == loc.Y && nampla.Z == loc.Z {
+			if nampla.PN == loc.PN {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: planet is already named PL %s.\n", cmd.Line, cmd, nampla.Name)
+				return
+			}
+			present = true
+		}
+	}
+	for _, ship := range sp.Ships {
+		if ship.X == loc.X && ship.Y == loc.Y && ship.Z == loc.Z && ship.Status != fh.UNDER_CONSTRUCTION {
+			present = true
+		}
+	}
+	if !present {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no ships or colonies in that star system.\n", cmd.Line, cmd)
+		return
+	}
+	nampla := &fh.NamedPlanetData{Name: name, X: loc.X, Y: loc.Y, Z: loc.Z, PN: loc.PN, Message: planet.Message, Planet: planet}
+	sp.Namplas = append(sp.Namplas, nampla)
+	sp.NumNamplas = len(sp.Namplas)
+	t.Logf(sp, "Planet %d %d %d #%d was named PL %s.\n", loc.X, loc.Y, loc.Z, loc.PN, name)
+	// a message attached to the planet goes with its name
+	if m := t.Galaxy.GetEventMessage(nampla.Message); m != nil {
+		t.eventMessages(sp, []*fh.EventMessage{m}, loc.X, loc.Y, loc.Z)
+	}
+}
+
+// scan reports the star system that a ship is in.
+func (t *Turn) scan(sp *fh.SpeciesData, cmd *orders.Command) {
+	ship := sp.GetShipByName(cmd.Args[0].Name)
+	if ship == nil || ship.Status == fh.UNDER_CONSTRUCTION {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such ship.\n", cmd.Line, cmd)
+		return
+	}
+	star := t.Galaxy.GetStarAt(ship.X, ship.Y, ship.Z)
+	if star == nil {
+		t.Logf(sp, "\nScan by %s: there is no star system at %d %d %d.\n", ship.Display(), ship.X, ship.Y, ship.Z)
+		return
+	}
+	w := &strings.Builder{}
+	if err := star.Scan(w, sp); err != nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: %v\n", cmd.Line, cmd, err)
+		return
+	}
+	t.Logf(sp, "\nScan by %s:\n%s", ship.Display(), w.String())
+	t.eventMessages(sp, t.Galaxy.EventMessagesInSystem(ship.X, ship.Y, ship.Z), ship.X, ship.Y, ship.Z)
+}
+
+// eventMessages adds messages from the catalogue found at a location to
+// the log of a species. Each message is only added the first time the
+// species sees it.
+func (t *Turn) eventMessages(sp *fh.SpeciesData, messages []*fh.EventMessage, x, y, z int) {
+	for _, m := range messages {
+		if !m.Deliver(sp) {
+			continue
+		}
+		if m.Title != "" {
+			t.Logf(sp, "\n%s\n", m.Title)
+		} else {
+			t.Logf(sp, "\nMessage found at %d %d %d:\n", x, y, z)
+		}
+		t.Logf(sp, "%s\n", strings.Join(m.Text, "\n"))
+	}
+}
+
+// telescope lists the star systems within range of the gravitic
+// telescopes on a ship. The range, in parsecs, is one tenth of the
+// species' gravitics tech level.
+func (t *Turn) telescope(sp *fh.SpeciesData, cmd *orders.Command) {
+	ship := sp.GetShipByName(cmd.Args[0].Name)
+	if ship == nil || ship.Status == fh.UNDER_CONSTRUCTION {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such ship.\n", cmd.Line, cmd)
+		return
+	} else if ship.ItemQuantity[fh.GT] == 0 {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: ship has no gravitic telescope units.\n", cmd.Line, cmd)
+		return
+	}
+	telescopeRange := sp.TechLevel[fh.GV] / 10
+	t.Logf(sp, "\nTelescope results from %s (range %d parsecs):\n", ship.Display(), telescopeRange)
+	for _, star := range t.Galaxy.AllStars() {
+		if star == nil || fh.DistanceSquared(ship.X, ship.Y, ship.Z, star.X, star.Y, star.Z) > telescopeRange*telescopeRange {
+			continue
+		}
+		t.Logf(sp, "\t%2d %2d %2d  %s%s%d  %d planets\n", star.X, star.Y, star.Z, star.Type.Char(), star.Color.Char(), star.Size, star.NumPlanets)
+	}
+}
+
+// terraform uses terraforming plants on a named planet. Each group of
+// three plants moves the temperature class or, once that matches the
+// home planet, the pressure class one step closer to the home planet.
+func (t *Turn) terraform(sp *fh.SpeciesData, cmd *orders.Command) {
+	amount := 0
+	if cmd.Args[0].Kind == orders.NUMBER {
+		amount = cmd.Args[0].Number
+	}
+	nampla := sp.GetNamplaByName(cmd.Args[len(cmd.Args)-1].Name)
+	if nampla == nil {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+		return
+	}
+	planet := t.Galaxy.GetPlanet(nampla.X, nampla.Y, nampla.Z, nampla.PN)
+	if planet == nil || sp.HomePlanet == nil || planet == sp.HomePlanet {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: planet can't be terraformed.\n", cmd.Line, cmd)
+		return
+	}
+
+	steps := nampla.ItemQuantity[fh.TP] / 3
+	if amount != 0 && amount < steps {
+		steps = amount
+	}
+	done := 0
+	for ; done < steps; done++ {
+		if d := sp.HomePlanet.TemperatureClass - planet.TemperatureClass; d != 0 {
+			planet.TemperatureClass += sign(d)
+		} else if d := sp.HomePlanet.PressureClass - planet.PressureClass; d != 0 {
+			planet.PressureClass += sign(d)
+		} else {
+			break
+		}
+	}
+	if done == 0 {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: nothing to terraform.\n", cmd.Line, cmd)
+		return
+	}
+	nampla.ItemQuantity[fh.TP] -= 3 * done
+	t.Logf(sp, "PL %s was terraformed %d times and now needs life support level %d.\n", nampla.Name, done, sp.LifeSupportNeeded(planet))
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
+
+// transfer moves items between ships and named planets at the same location.
+func (t *Turn) transfer(sp *fh.SpeciesData, cmd *orders.Command) {
+	amount, item := cmd.Args[0].Number, fh.Item(cmd.Args[1].Code)
+	src, srcLoc, ok := t.inventory(sp, cmd.Args[2])
+	if !ok {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you don't have %s.\n", cmd.Line, cmd, cmd.Args[2])
+		return
+	}
+	dst, dstLoc, ok := t.inventory(sp, cmd.Args[3])
+	if !ok {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you don't have %s.\n", cmd.Line, cmd, cmd.Args[3])
+		return
+	} else if srcLoc != dstLoc {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: source and destination are not at the same location.\n", cmd.Line, cmd)
+		return
+	}
+	if amount == 0 || amount > src[item] {
+		amount = src[item]
+	}
+	if cmd.Args[3].Kind == orders.SHIP {
+		ship := sp.GetShipByName(cmd.Args[3].Name)
+		if space := ship.Capacity() - ship.CargoUsed(); amount*item.CarryCapacity() > space {
+			amount = space / item.CarryCapacity()
+		}
+	}
+	if amount <= 0 {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: there are no %ss to transfer or no room for them.\n", cmd.Line, cmd, item.Abbr())
+		return
+	}
+	src[item] -= amount
+	dst[item] += amount
+	if item == fh.CU && cmd.Args[2].Kind == orders.PLANET_ID && cmd.Args[3].Kind == orders.SHIP {
+		// remember where the colonists came from
+		sp.GetShipByName(cmd.Args[3].Name).LoadingPoint = sp.GetNamplaByName(cmd.Args[2].Name).Name
+	}
+	t.Logf(sp, "%d %ss were transferred from %s to %s.\n", amount, item.Abbr(), cmd.Args[2], cmd.Args[3])
+}
+
+// location is a star system and planet number.
+type location struct{ x, y, z, pn int }
+
+// inventory returns the items held by the ship or named planet given
+// by an argument, along with its location.
+func (t *Turn) inventory(sp *fh.SpeciesData, arg *orders.Arg) (*[fh.MAX_ITEMS]int, location, bool) {
+	if arg.Kind == orders.SHIP {
+		ship := sp.GetShipByName(arg.Name)
+		if ship == nil || ship.Status == fh.UNDER_CONSTRUCTION {
+			return nil, location{}, false
+		}
+		return &ship.ItemQuantity, location{ship.X, ship.Y, ship.Z, ship.PN}, true
+	}
+	nampla := sp.GetNamplaByName(arg.Name)
+	if nampla == nil {
+		return nil, location{}, false
+	}
+	return &nampla.ItemQuantity, location{nampla.X, nampla.Y, nampla.Z, nampla.PN}, true
+}
+
+// unload moves the colonists and colonial units carried by a ship to
+// the named planet it is at and installs as many units as the colonists
+// can run, up to the numbers that were set by DEVELOP orders.
+func (t *Turn) unload(sp *fh.SpeciesData, cmd *orders.Command) {
+	ship := sp.GetShipByName(cmd.Args[0].Name)
+	if ship == nil || ship.Status == fh.UNDER_CONSTRUCTION {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such ship.\n", cmd.Line, cmd)
+		return
+	}
+	var nampla *fh.NamedPlanetData
+	for _, n := range sp.Namplas {
+		if n.X == ship.X && n.Y == ship.Y && n.Z == ship.Z && n.PN == ship.PN {
+			nampla = n
+		}
+	}
+	if nampla == nil || ship.PN == 0 {
+		t.Logf(sp, "!!! Order ignored on line %d: %q: ship is not at one of your named planets.\n", cmd.Line, cmd)
+		return
+	}
+
+	for _, item := range []int{fh.CU, fh.IU, fh.AU} {
+		nampla.ItemQuantity[item] += ship.ItemQuantity[item]
+		ship.ItemQuantity[item] = 0
+	}
+	ship.LoadingPoint, ship.UnloadingPoint = "", ""
+
+	ius := min(nampla.AutoIUs, nampla.ItemQuantity[fh.IU], nampla.ItemQuantity[fh.CU])
+	nampla.ItemQuantity[fh.IU] -= ius
+	nampla.ItemQuantity[fh.CU] -= ius
+	aus := min(nampla.AutoAUs, nampla.ItemQuantity[fh.AU], nampla.ItemQuantity[fh.CU])
+	nampla.ItemQuantity[fh.AU] -= aus
+	nampla.ItemQuantity[fh.CU] -= aus
+	nampla.AutoIUs, nampla.AutoAUs = nampla.AutoIUs-ius, nampla.AutoAUs-aus
+	nampla.IUsToInstall += ius
+	nampla.AUsToInstall += aus
+	if ius+aus != 0 {
+		nampla.Status |= fh.COLONY
+	}
+	t.Logf(sp, "%s unloaded at PL %s; %d IUs and %d AUs will be installed.\n", ship.Display(), nampla.Name, ius, aus)
+}
@@ -0,0 +1,75 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	iu := &orders.Arg{Kind: orders.ITEM_CLASS, Code: fh.IU}
+	cu := &orders.Arg{Kind: orders.ITEM_CLASS, Code: fh.CU}
+	home := &orders.Arg{Kind: orders.PLANET_ID, Name: "Home"}
+	for _, tc := range []struct {
+		args      []*orders.Arg
+		installed int
+		cus       int
+	}{
+		{[]*orders.Arg{number(5), iu, home}, 5, 5},
+		{[]*orders.Arg{iu, home}, 8, 2},
+		{[]*orders.Arg{number(50), iu, home}, 8, 2},
+		{[]*orders.Arg{number(3), cu, home}, 0, 10},
+	} {
+		turn, sp := newTestTurn()
+		nampla := sp.Namplas[0]
+		nampla.ItemQuantity[fh.IU] = 8
+		cmd := &orders.Command{Code: fh.INSTALL, Args: tc.args}
+		turn.install(sp, cmd)
+		if nampla.IUsToInstall != tc.installed || nampla.ItemQuantity[fh.CU] != tc.cus || nampla.ItemQuantity[fh.IU] != 8-tc.installed {
+			t.Errorf("%s: installing %d, %d CUs, %d IUs left, want %d, %d, %d", cmd,
+				nampla.IUsToInstall, nampla.ItemQuantity[fh.CU], nampla.ItemQuantity[fh.IU], tc.installed, tc.cus, 8-tc.installed)
+		}
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	cu := &orders.Arg{Kind: orders.ITEM_CLASS, Code: fh.CU}
+	home := &orders.Arg{Kind: orders.PLANET_ID, Name: "Home"}
+	colony := &orders.Arg{Kind: orders.PLANET_ID, Name: "Colony"}
+	ship := &orders.Arg{Kind: orders.SHIP, Code: fh.DD, Name: "Alpha"}
+	for _, tc := range []struct {
+		args  []*orders.Arg
+		moved int
+	}{
+		{[]*orders.Arg{number(3), cu, home, ship}, 3},
+		{[]*orders.Arg{number(0), cu, home, ship}, 10},
+		{[]*orders.Arg{number(3), cu, home, colony}, 0}, // not at the same location
+	} {
+		turn, sp := newTestTurn()
+		nampla := sp.Namplas[0]
+		cmd := &orders.Command{Code: fh.TRANSFER, Args: tc.args}
+		turn.transfer(sp, cmd)
+		moved := 10 - nampla.ItemQuantity[fh.CU]
+		if moved != tc.moved || sp.Ships[0].ItemQuantity[fh.CU]+sp.Namplas[1].ItemQuantity[fh.CU] != tc.moved {
+			t.Errorf("%s: moved %d CUs, want %d", cmd, moved, tc.moved)
+		}
+	}
+}
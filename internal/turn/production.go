@@ -0,0 +1,607 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+)
+
+// ESTIMATE_COST is the cost of estimating the tech levels of another species.
+const ESTIMATE_COST = 25
+
+// production holds the state of the planet that is currently producing.
+type production struct {
+	sp           *fh.SpeciesData
+	nampla       *fh.NamedPlanetData
+	balance      int  // economic units left to spend on this planet
+	shipyardDone bool // a shipyard was built this turn
+	shipsBuilt   int  // ships built this turn, limited by shipyards
+}
+
+// Production runs the PRODUCTION section of every species' orders.
+// Every named planet produces economic units from its mining and
+// manufacturing bases, less fleet maintenance. Planets may spend their
+// own production and the species' bank; whatever is left over at the
+// end of the phase is added to the bank.
+func (t *Turn) Production() error {
+	for _, sp := range t.AllSpecies() {
+		balances := t.startProduction(sp)
+
+		var p *production
+		done := make(map[*fh.NamedPlanetData]*production)
+		for _, cmd := range t.Commands(sp, orders.PRODUCTION) {
+			if cmd.Code == fh.PRODUCTION {
+				nampla := sp.GetNamplaByName(cmd.Args[0].Name)
+				if nampla == nil {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+					p = nil
+				} else if done[nampla] != nil {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: production already started on this planet.\n", cmd.Line, cmd)
+					p = nil
+				} else if nampla.Status&(fh.MINING_COLONY|fh.RESORT_COLONY) != 0 {
+					t.Logf(sp, "!!! Order ignored on line %d: %q: mining and resort colonies can't build anything.\n", cmd.Line, cmd)
+					p = nil
+				} else {
+					p = &production{sp: sp, nampla: nampla, balance: balances[nampla]}
+					done[nampla] = p
+					t.Logf(sp, "\nStart of production on PL %s. (Balance is %d, %d in the bank.)\n", nampla.Name, p.balance, sp.EconUnits)
+				}
+				continue
+			} else if p == nil {
+				t.Logf(sp, "!!! Order ignored on line %d: %q: no valid PRODUCTION order in effect.\n", cmd.Line, cmd)
+				continue
+			}
+
+			switch cmd.Code {
+			case fh.BUILD:
+				t.build(p, cmd)
+			case fh.CONTINUE:
+				t.continueShip(p, cmd)
+			case fh.DEVELOP:
+				t.develop(p, cmd)
+			case fh.ESTIMATE:
+				t.estimate(p, cmd)
+			case fh.HIDE:
+				t.hide(p, cmd)
+			case fh.RECYCLE:
+				t.recycle(p, cmd)
+			case fh.RESEARCH:
+				t.research(p, cmd)
+			case fh.SHIPYARD:
+				t.shipyard(p, cmd)
+			case fh.UPGRADE:
+				t.upgrade(p, cmd)
+			default:
+				t.Logf(sp, "!!! Order ignored on line %d: %q: %s is not supported yet.\n", cmd.Line, cmd, fh.CommandName(cmd.Code))
+			}
+		}
+
+		// unspent production goes into the bank
+		unspent := 0
+		for _, nampla := range sp.Namplas {
+			if p, ok := done[nampla]; ok {
+				unspent += p.balance
+			} else {
+				unspent += balances[nampla]
+			}
+		}
+		sp.EconUnits += unspent
+		t.Logf(sp, "\nUnspent production of %d economic units was added to the bank, which now holds %d.\n", unspent, sp.EconUnits)
+	}
+	t.locations = nil
+	return nil
+}
+
+// startProduction computes the production of each named planet of a
+// species, after fleet maintenance, and logs it.
+func (t *Turn) startProduction(sp *fh.SpeciesData) map[*fh.NamedPlanetData]int {
+	balances := make(map[*fh.NamedPlanetData]int)
+	total := 0
+	for _, nampla := range sp.Namplas {
+		balances[nampla] = t.planetProduction(sp, nampla)
+		total += balances[nampla]
+	}
+
+	sp.FleetCost = FleetCost(sp)
+	sp.FleetPercentCost = 0
+	if total > 0 {
+		sp.FleetPercentCost = (10000 * sp.FleetCost) / total
+		if sp.FleetPercentCost > 10000 {
+			sp.FleetPercentCost = 10000
+		}
+	}
+
+	for _, nampla := range sp.Namplas {
+		maintenance := (balances[nampla] * sp.FleetPercentCost) / 10000
+		balances[nampla] -= maintenance
+		if balances[nampla] != 0 || maintenance != 0 {
+			t.Logf(sp, "PL %s produced %d economic units after %d for fleet maintenance.\n", nampla.Name, balances[nampla], maintenance)
+		}
+	}
+	return balances
+}
+
+// planetProduction returns the economic units produced by a named
+// planet before fleet maintenance. Production is limited by both raw
+// materials and manufacturing capacity and is reduced when the species
+// doesn't have enough life support for the planet. Mining and resort
+// colonies only produce two-thirds of their raw materials or capacity.
+func (t *Turn) planetProduction(sp *fh.SpeciesData, nampla *fh.NamedPlanetData) int {
+	planet := t.Galaxy.GetPlanet(nampla.X, nampla.Y, nampla.Z, nampla.PN)
+	if planet == nil || nampla.Status&fh.DISBANDED_COLONY != 0 {
+		return 0
+	}
+
+	raw_material_units := 0
+	if planet.MiningDifficulty > 0 {
+		raw_material_units = (10 * sp.TechLevel[fh.MI] * nampla.MIBase) / planet.MiningDifficulty
+	}
+	production_capacity := (sp.TechLevel[fh.MA] * nampla.MABase) / 10
+
+	if sp.HomePlanet != nil {
+		if ls_needed := sp.LifeSupportNeeded(planet); ls_needed != 0 && nampla.Status&fh.HOME_PLANET == 0 {
+			production_penalty := 100
+			if sp.TechLevel[fh.LS] > 0 {
+				production_penalty = (100 * ls_needed) / sp.TechLevel[fh.LS]
+			}
+			if production_penalty > 100 {
+				production_penalty = 100
+			}
+			raw_material_units -= (production_penalty * raw_material_units) / 100
+			production_capacity -= (production_penalty * production_capacity) / 100
+		}
+	}
+
+	var balance int
+	if nampla.Status&fh.MINING_COLONY != 0 {
+		balance = (2 * raw_material_units) / 3
+	} else if nampla.Status&fh.RESORT_COLONY != 0 {
+		balance = (2 * production_capacity) / 3
+	} else if raw_material_units < production_capacity {
+		balance = raw_material_units
+	} else {
+		balance = production_capacity
+	}
+
+	if planet.EconEfficiency < 100 && nampla.Status&fh.HOME_PLANET == 0 {
+		balance = (balance*planet.EconEfficiency + 50) / 100
+	}
+	return balance
+}
+
+// FleetCost returns the maintenance cost, in economic units per turn,
+// of a species' fleet. Ships still under construction don't need
+// maintenance.
+func FleetCost(sp *fh.SpeciesData) int {
+	cost := 0
+	for _, ship := range sp.Ships {
+		if ship.Status != fh.UNDER_CONSTRUCTION {
+			cost += ship.Class.MaintenanceCost(ship.Tonnage, ship.Type == fh.SUB_LIGHT)
+		}
+	}
+	return cost
+}
+
+// spend takes economic units from the planet's production and then
+// from the species' bank. It returns false, and spends nothing, if
+// there aren't enough or the amount is negative.
+func (p *production) spend(amount int) bool {
+	if amount < 0 || amount > p.available() {
+		return false
+	}
+	if amount <= p.balance {
+		p.balance -= amount
+	} else {
+		p.sp.EconUnits -= amount - p.balance
+		p.balance = 0
+	}
+	return true
+}
+
+// available returns the economic units the planet may spend.
+func (p *production) available() int {
+	return p.balance + p.sp.EconUnits
+}
+
+// orbiting returns true if the ship is at the producing planet.
+func (p *production) orbiting(ship *fh.ShipData) bool {
+	n := p.nampla
+	return ship.X == n.X && ship.Y == n.Y && ship.Z == n.Z && ship.PN == n.PN && ship.Status != fh.UNDER_CONSTRUCTION
+}
+
+func (t *Turn) build(p *production, cmd *orders.Command) {
+	if cmd.Args[0].Kind == orders.SHIP {
+		t.buildShip(p, cmd)
+		return
+	} else if cmd.Args[1].Kind != orders.ITEM_CLASS {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: new ships must be given a name.\n", cmd.Line, cmd)
+		return
+	}
+
+	amount, item := cmd.Args[0].Number, fh.Item(cmd.Args[1].Code)
+	if !item.Assigned() {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: %s can't be built.\n", cmd.Line, cmd, item)
+		return
+	} else if tech, level := item.TechRequired(); p.sp.TechLevel[tech] < level {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: you need %s tech level %d to build %s.\n", cmd.Line, cmd, fh.TechAbbr[tech], level, item)
+		return
+	}
+
+	// find where the items will be stored
+	inventory := &p.nampla.ItemQuantity
+	var ship *fh.ShipData
+	if len(cmd.Args) > 2 {
+		dest := cmd.Args[2]
+		if dest.Kind == orders.SHIP {
+			ship = p.sp.GetShipByName(dest.Name)
+			if ship == nil || !p.orbiting(ship) {
+				t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship is not at the producing planet.\n", cmd.Line, cmd)
+				return
+			}
+			inventory = &ship.ItemQuantity
+		} else {
+			nampla := p.sp.GetNamplaByName(dest.Name)
+			if nampla == nil || nampla.X != p.nampla.X || nampla.Y != p.nampla.Y || nampla.Z != p.nampla.Z {
+				t.Logf(p.sp, "!!! Order ignored on line %d: %q: planet is not in the same star system.\n", cmd.Line, cmd)
+				return
+			}
+			inventory = &nampla.ItemQuantity
+		}
+	}
+
+	// build as many as can be paid for
+	cost := item.Cost()
+	if amount == 0 || amount*cost > p.available() {
+		amount = p.available() / cost
+	}
+	if item == fh.CU && amount > p.nampla.PopUnits {
+		amount = p.nampla.PopUnits
+	}
+	if ship != nil && amount*item.CarryCapacity() > ship.Capacity()-ship.CargoUsed() {
+		amount = (ship.Capacity() - ship.CargoUsed()) / item.CarryCapacity()
+	}
+	if amount == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds, population or cargo space.\n", cmd.Line, cmd)
+		return
+	}
+	p.spend(amount * cost)
+	if item == fh.CU {
+		p.nampla.PopUnits -= amount
+	}
+	inventory[item] += amount
+	t.Logf(p.sp, "%s built on PL %s.\n", itemCount(amount, item), p.nampla.Name)
+}
+
+func (t *Turn) buildShip(p *production, cmd *orders.Command) {
+	arg := cmd.Args[0]
+	if p.sp.GetShipByName(arg.Name) != nil {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: you already have a ship with that name.\n", cmd.Line, cmd)
+		return
+	}
+
+	ship := &fh.ShipData{
+		Name:    arg.Name,
+		X:       p.nampla.X,
+		Y:       p.nampla.Y,
+		Z:       p.nampla.Z,
+		PN:      p.nampla.PN,
+		Status:  fh.IN_ORBIT,
+		Type:    fh.FTL,
+		Class:   fh.ShipClass(arg.Code),
+		Tonnage: arg.Tonnage,
+	}
+	if ship.Class == fh.BA {
+		ship.Type = fh.STARBASE
+	} else if arg.SubLight {
+		ship.Type = fh.SUB_LIGHT
+	}
+
+	if ma := ship.Class.MinManufacturing(ship.Tonnage); p.sp.TechLevel[fh.MA] < ma {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: you need MA tech level %d to build this ship.\n", cmd.Line, cmd, ma)
+		return
+	} else if ship.Type != fh.STARBASE && p.shipsBuilt >= p.nampla.Shipyards {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: all shipyards on PL %s are in use.\n", cmd.Line, cmd, p.nampla.Name)
+		return
+	} else if p.available() == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds.\n", cmd.Line, cmd)
+		return
+	}
+	if ship.Type != fh.STARBASE {
+		p.shipsBuilt++
+	}
+	p.sp.Ships = append(p.sp.Ships, ship)
+	p.sp.NumShips = len(p.sp.Ships)
+
+	// a ship that can't be paid for in full is left under construction
+	cost := ship.Cost()
+	if cost > p.available() {
+		ship.Status, ship.RemainingCost = fh.UNDER_CONSTRUCTION, cost-p.available()
+		p.spend(p.available())
+		t.Logf(p.sp, "Construction of %s was started at PL %s; %d is needed to complete it.\n", ship.Display(), p.nampla.Name, ship.RemainingCost)
+		return
+	}
+	p.spend(cost)
+	t.Logf(p.sp, "%s was built at PL %s.\n", ship.Display(), p.nampla.Name)
+}
+
+// continueShip pays for more of a ship that is under construction.
+func (t *Turn) continueShip(p *production, cmd *orders.Command) {
+	ship := p.sp.GetShipByName(cmd.Args[0].Name)
+	n := p.nampla
+	if ship == nil || ship.X != n.X || ship.Y != n.Y || ship.Z != n.Z || ship.PN != n.PN {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship is not at the producing planet.\n", cmd.Line, cmd)
+		return
+	} else if ship.Status != fh.UNDER_CONSTRUCTION {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship is not under construction.\n", cmd.Line, cmd)
+		return
+	} else if ship.Type != fh.STARBASE && p.shipsBuilt >= p.nampla.Shipyards {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: all shipyards on PL %s are in use.\n", cmd.Line, cmd, p.nampla.Name)
+		return
+	}
+	amount := ship.RemainingCost
+	if len(cmd.Args) > 1 && cmd.Args[1].Number < amount {
+		amount = cmd.Args[1].Number
+	}
+	if amount > p.available() {
+		amount = p.available()
+	}
+	if amount == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds.\n", cmd.Line, cmd)
+		return
+	}
+	if ship.Type != fh.STARBASE {
+		p.shipsBuilt++
+	}
+	p.spend(amount)
+	ship.RemainingCost -= amount
+	if ship.RemainingCost > 0 {
+		t.Logf(p.sp, "Spent %d on %s; %d is needed to complete it.\n", amount, ship.Display(), ship.RemainingCost)
+		return
+	}
+	ship.Status = fh.IN_ORBIT
+	t.Logf(p.sp, "%s was completed at PL %s.\n", ship.Display(), p.nampla.Name)
+}
+
+// develop builds colonial units, each of which is one colonist unit plus
+// a mining or manufacturing unit, for a colony.
+func (t *Turn) develop(p *production, cmd *orders.Command) {
+	amount, target := 0, p.nampla
+	var ship *fh.ShipData
+	for _, arg := range cmd.Args {
+		switch arg.Kind {
+		case orders.NUMBER:
+			amount = arg.Number
+		case orders.PLANET_ID:
+			if target = p.sp.GetNamplaByName(arg.Name); target == nil {
+				t.Logf(p.sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+				return
+			}
+		case orders.SHIP:
+			if ship = p.sp.GetShipByName(arg.Name); ship == nil || !p.orbiting(ship) {
+				t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship is not at the producing planet.\n", cmd.Line, cmd)
+				return
+			}
+		}
+	}
+	sameSystem := target.X == p.nampla.X && target.Y == p.nampla.Y && target.Z == p.nampla.Z
+	if target.Status&fh.HOME_PLANET != 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: only colonies can be developed.\n", cmd.Line, cmd)
+		return
+	} else if !sameSystem && ship == nil {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: a ship is needed to carry units to another system.\n", cmd.Line, cmd)
+		return
+	}
+
+	// each unit costs two economic units and one population unit
+	units := p.available() / 2
+	if amount != 0 && amount/2 < units {
+		units = amount / 2
+	}
+	if units > p.nampla.PopUnits {
+		units = p.nampla.PopUnits
+	}
+	if ship != nil {
+		// each unit is a colonist unit plus a mining or manufacturing unit
+		perUnit := fh.Item(fh.CU).CarryCapacity() + fh.Item(fh.IU).CarryCapacity()
+		if space := ship.Capacity() - ship.CargoUsed(); units*perUnit > space {
+			units = space / perUnit
+		}
+	}
+	if units == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds, population or cargo space.\n", cmd.Line, cmd)
+		return
+	}
+	p.spend(2 * units)
+	p.nampla.PopUnits -= units
+
+	// mining colonies get mining units, resort colonies get manufacturing
+	// units and other colonies get whatever keeps their bases balanced.
+	ius, aus := 0, 0
+	mi, ma := target.MIBase, target.MABase
+	for i := 0; i < units; i++ {
+		if target.Status&fh.MINING_COLONY != 0 || (target.Status&fh.RESORT_COLONY == 0 && mi <= ma) {
+			ius, mi = ius+1, mi+1
+		} else {
+			aus, ma = aus+1, ma+1
+		}
+	}
+
+	if sameSystem && ship == nil {
+		target.IUsToInstall += ius
+		target.AUsToInstall += aus
+	} else {
+		ship.ItemQuantity[fh.CU] += units
+		ship.ItemQuantity[fh.IU] += ius
+		ship.ItemQuantity[fh.AU] += aus
+		ship.LoadingPoint, ship.UnloadingPoint = p.nampla.Name, target.Name
+		target.AutoIUs += ius
+		target.AutoAUs += aus
+	}
+	t.Logf(p.sp, "PL %s was developed with %d CUs, %d IUs and %d AUs.\n", target.Name, units, ius, aus)
+}
+
+// estimate reports the approximate tech levels of another species.
+func (t *Turn) estimate(p *production, cmd *orders.Command) {
+	other := t.getSpecies(cmd.Args[0])
+	if other == nil || !p.sp.HasMet(other) {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: you have not made contact with this species.\n", cmd.Line, cmd)
+		return
+	} else if !p.spend(ESTIMATE_COST) {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds.\n", cmd.Line, cmd)
+		return
+	}
+	t.Logf(p.sp, "Estimate of the technology of SP %s:", other.Name)
+	rng := t.Galaxy.Stream(fh.PRODUCTION_STREAM)
+	for tech, level := range other.TechLevel {
+		// estimates are within 20% of the actual level
+		estimate := level + (level*(rng.Roll(41)-21))/100
+		if estimate < 0 {
+			estimate = 0
+		}
+		if tech != fh.MI {
+			t.Logf(p.sp, ",")
+		}
+		t.Logf(p.sp, " %s = %d", fh.TechAbbr[tech], estimate)
+	}
+	t.Logf(p.sp, ".\n")
+}
+
+func (t *Turn) hide(p *production, cmd *orders.Command) {
+	nampla := p.sp.GetNamplaByName(cmd.Args[0].Name)
+	if nampla == nil {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: you have no such planet.\n", cmd.Line, cmd)
+		return
+	}
+	nampla.Hiding = true
+}
+
+// recycle turns items or a ship back into economic units. Items are
+// worth half their cost. Ships are worth half their cost when new and
+// lose value as they age; any cargo is recycled with the ship.
+func (t *Turn) recycle(p *production, cmd *orders.Command) {
+	if cmd.Args[0].Kind == orders.SHIP {
+		ship := p.sp.GetShipByName(cmd.Args[0].Name)
+		if ship == nil || !p.orbiting(ship) {
+			t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship is not at the producing planet.\n", cmd.Line, cmd)
+			return
+		}
+		value := (ship.Cost() * (60 - ship.Age)) / 100
+		if value < 0 {
+			value = 0
+		}
+		for item, quantity := range ship.ItemQuantity {
+			value += (quantity * fh.Item(item).Cost()) / 2
+		}
+		var ships []*fh.ShipData
+		for _, s := range p.sp.Ships {
+			if s != ship {
+				ships = append(ships, s)
+			}
+		}
+		p.sp.Ships, p.sp.NumShips = ships, len(ships)
+		p.balance += value
+		t.Logf(p.sp, "%s was recycled for %d economic units.\n", ship.Display(), value)
+		return
+	}
+
+	amount, item := cmd.Args[0].Number, fh.Item(cmd.Args[1].Code)
+	if amount == 0 || amount > p.nampla.ItemQuantity[item] {
+		amount = p.nampla.ItemQuantity[item]
+	}
+	if amount == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: PL %s has no %s.\n", cmd.Line, cmd, p.nampla.Name, item)
+		return
+	}
+	value := (amount * item.Cost()) / 2
+	p.nampla.ItemQuantity[item] -= amount
+	p.balance += value
+	t.Logf(p.sp, "%s recycled for %d economic units.\n", itemCount(amount, item), value)
+}
+
+// research adds experience points to a tech. They are turned into
+// tech levels at the end of the turn.
+func (t *Turn) research(p *production, cmd *orders.Command) {
+	amount, tech := cmd.Args[0].Number, cmd.Args[1].Code
+	if amount == 0 || amount > p.available() {
+		amount = p.available()
+	}
+	if amount == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds.\n", cmd.Line, cmd)
+		return
+	}
+	p.spend(amount)
+	p.sp.TechEps[tech] += amount
+	t.Logf(p.sp, "Spent %d on %s research.\n", amount, fh.TechName[tech])
+}
+
+// shipyard builds one shipyard. The cost depends on manufacturing tech.
+func (t *Turn) shipyard(p *production, cmd *orders.Command) {
+	cost := 10 * p.sp.TechLevel[fh.MA]
+	if p.shipyardDone {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: only one shipyard can be built per planet per turn.\n", cmd.Line, cmd)
+		return
+	} else if !p.spend(cost) {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds, a shipyard costs %d.\n", cmd.Line, cmd, cost)
+		return
+	}
+	p.shipyardDone = true
+	p.nampla.Shipyards++
+	t.Logf(p.sp, "A shipyard was built on PL %s, which now has %d.\n", p.nampla.Name, p.nampla.Shipyards)
+}
+
+// upgrade reduces the age of a ship. Each year of age removed costs
+// one fortieth of the cost of the ship.
+func (t *Turn) upgrade(p *production, cmd *orders.Command) {
+	ship := p.sp.GetShipByName(cmd.Args[0].Name)
+	if ship == nil || !p.orbiting(ship) {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship is not at the producing planet.\n", cmd.Line, cmd)
+		return
+	} else if ship.Age <= 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: ship doesn't need upgrading.\n", cmd.Line, cmd)
+		return
+	}
+	perYear := ship.Cost() / 40
+	if perYear < 1 {
+		perYear = 1
+	}
+	years := ship.Age
+	if len(cmd.Args) > 1 && cmd.Args[1].Number/perYear < years {
+		years = cmd.Args[1].Number / perYear
+	}
+	if years*perYear > p.available() {
+		years = p.available() / perYear
+	}
+	if years == 0 {
+		t.Logf(p.sp, "!!! Order ignored on line %d: %q: insufficient funds.\n", cmd.Line, cmd)
+		return
+	}
+	p.spend(years * perYear)
+	ship.Age -= years
+	t.Logf(p.sp, "%s was upgraded and is now %d years old.\n", ship.Display(), ship.Age)
+}
+
+// itemCount returns a quantity of items for the log, e.g. "10 Colonist Units were".
+func itemCount(n int, item fh.Item) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s was", item)
+	}
+	return fmt.Sprintf("%d %ss were", n, item)
+}
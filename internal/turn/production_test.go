@@ -0,0 +1,99 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"strings"
+	"testing"
+)
+
+// newTestTurn returns a turn for a single species with a home planet,
+// a colony in the same system and a ship orbiting the home planet.
+func newTestTurn() (*Turn, *fh.SpeciesData) {
+	home := &fh.NamedPlanetData{Name: "Home", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.HOME_PLANET, PopUnits: 100, Shipyards: 1}
+	colony := &fh.NamedPlanetData{Name: "Colony", X: 1, Y: 2, Z: 3, PN: 2, Status: fh.COLONY}
+	home.ItemQuantity[fh.CU] = 10
+	ship := &fh.ShipData{Name: "Alpha", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.IN_ORBIT, Class: fh.DD, Tonnage: fh.ShipClass(fh.DD).Tonnage(), Age: 5}
+	sp := &fh.SpeciesData{ID: "01", Number: 1, Name: "Test", EconUnits: 50, Namplas: []*fh.NamedPlanetData{home, colony}, Ships: []*fh.ShipData{ship}}
+	sp.TechLevel = [6]int{10, 10, 10, 10, 10, 10}
+	g := &fh.GalaxyData{Species: map[string]*fh.SpeciesData{sp.ID: sp}}
+	t := &Turn{Galaxy: g, Orders: make(map[string]*orders.Orders), logs: make(map[string]*strings.Builder)}
+	return t, sp
+}
+
+func number(n int) *orders.Arg {
+	return &orders.Arg{Kind: orders.NUMBER, Number: n}
+}
+
+func TestProductionSpend(t *testing.T) {
+	_, sp := newTestTurn()
+	for _, tc := range []struct {
+		amount  int
+		ok      bool
+		balance int
+		bank    int
+	}{
+		{-10, false, 100, 50},
+		{0, true, 100, 50},
+		{40, true, 60, 50},
+		{120, true, 0, 30},
+		{151, false, 100, 50},
+	} {
+		sp.EconUnits = 50
+		p := &production{sp: sp, nampla: sp.Namplas[0], balance: 100}
+		if ok := p.spend(tc.amount); ok != tc.ok || p.balance != tc.balance || sp.EconUnits != tc.bank {
+			t.Errorf("spend(%d): got %v, %d, %d, want %v, %d, %d", tc.amount, ok, p.balance, sp.EconUnits, tc.ok, tc.balance, tc.bank)
+		}
+	}
+}
+
+func TestResearch(t *testing.T) {
+	turn, sp := newTestTurn()
+	p := &production{sp: sp, nampla: sp.Namplas[0], balance: 100}
+	turn.research(p, &orders.Command{Code: fh.RESEARCH, Args: []*orders.Arg{number(30), {Kind: orders.TECH_ID, Code: fh.MI}}})
+	if p.balance != 70 || sp.TechEps[fh.MI] != 30 {
+		t.Errorf("research: balance %d, eps %d, want 70 and 30", p.balance, sp.TechEps[fh.MI])
+	}
+}
+
+func TestDevelop(t *testing.T) {
+	colony := &orders.Arg{Kind: orders.PLANET_ID, Name: "Colony"}
+	ship := &orders.Arg{Kind: orders.SHIP, Code: fh.DD, Name: "Alpha"}
+	perUnit := fh.Item(fh.CU).CarryCapacity() + fh.Item(fh.IU).CarryCapacity()
+	for _, tc := range []struct {
+		args  []*orders.Arg
+		space int // free cargo space on the ship
+		units int
+	}{
+		{[]*orders.Arg{number(20), colony}, 0, 10},
+		{[]*orders.Arg{number(20), colony, ship}, 3 * perUnit, 3}, // the ship limits it even within the system
+		{[]*orders.Arg{number(20), colony, ship}, 0, 0},
+	} {
+		turn, sp := newTestTurn()
+		alpha := sp.Ships[0]
+		alpha.ItemQuantity[fh.RM] = (alpha.Capacity() - tc.space) / fh.Item(fh.RM).CarryCapacity()
+		p := &production{sp: sp, nampla: sp.Namplas[0], balance: 100}
+		turn.develop(p, &orders.Command{Code: fh.DEVELOP, Args: tc.args})
+		if units := 100 - sp.Namplas[0].PopUnits; units != tc.units || alpha.CargoUsed() > alpha.Capacity() {
+			t.Errorf("develop with %d free: %d units, %d of %d cargo used, want %d units", tc.space, units, alpha.CargoUsed(), alpha.Capacity(), tc.units)
+		}
+	}
+}
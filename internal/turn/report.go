@@ -0,0 +1,227 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Report writes the turn report for every species to spNN.rpt.tTT in
+// the report directory. The report includes everything written to the
+// species log during the turn, so the log is removed once the report
+// has been written.
+func (t *Turn) Report() error {
+	for _, sp := range t.AllSpecies() {
+		logName := filepath.Join(t.logDir, fmt.Sprintf("sp%s.log", sp.ID))
+		events, err := ioutil.ReadFile(logName)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// pick up anything logged by this phase that hasn't been written yet
+		if log, ok := t.logs[sp.ID]; ok {
+			events = append(events, log.String()...)
+			log.Reset()
+		}
+
+		name := filepath.Join(t.ReportDir, fmt.Sprintf("sp%s.rpt.t%d", sp.ID, t.Galaxy.TurnNumber))
+		w, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := t.WriteReport(w, sp, string(events)); err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if err := os.Remove(logName); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteReport writes the turn report for a species. Events is the text
+// logged for the species during the turn. The transactions the species
+// received, from the ledger, are listed after the events.
+func (t *Turn) WriteReport(w io.Writer, sp *fh.SpeciesData, events string) error {
+	r := &reportWriter{w: w}
+
+	r.printf("START OF TURN %d\n\n", t.Galaxy.TurnNumber)
+	r.printf("Species name: %s\n", sp.Name)
+	r.printf("Species number: %d\n", sp.Number)
+	r.printf("Government name: %s\n", sp.GovtName)
+	r.printf("Government type: %s\n", sp.GovtType)
+	if home := sp.HomeNampla; home != nil {
+		r.printf("Home planet: PL %s at %d %d %d #%d\n", home.Name, home.X, home.Y, home.Z, home.PN)
+	}
+
+	r.printf("\nTech Levels:\n")
+	for tech, level := range sp.TechLevel {
+		r.printf("   %-14s = %3d", fh.TechName[tech], level)
+		if sp.TechKnowledge[tech] > level {
+			r.printf("/%d", sp.TechKnowledge[tech])
+		}
+		if sp.TechEps[tech] != 0 {
+			r.printf("  (%d experience points)", sp.TechEps[tech])
+		}
+		r.printf("\n")
+	}
+
+	r.printf("\nEconomic units = %d\n", sp.EconUnits)
+	r.printf("Fleet maintenance cost = %d (%d.%02d%% of total production)\n",
+		sp.FleetCost, sp.FleetPercentCost/100, sp.FleetPercentCost%100)
+
+	r.diplomacy(sp, t.AllSpecies())
+
+	notices := t.notices(sp)
+	if strings.TrimSpace(events) != "" || len(notices) != 0 {
+		r.printf("\nEvents of the last turn:\n\n%s", events)
+		if events != "" && !strings.HasSuffix(events, "\n") {
+			r.printf("\n")
+		}
+		for _, line := range notices {
+			r.printf("%s\n", line)
+		}
+	}
+
+	r.printf("\n* * * * * * * * * * * * * * * * * * * * * * * * *\n")
+	for _, nampla := range sp.Namplas {
+		r.printf("\n%s PL %s at %d %d %d #%d\n", namplaStatus(nampla), nampla.Name, nampla.X, nampla.Y, nampla.Z, nampla.PN)
+		if nampla.Status&fh.DISBANDED_COLONY != 0 {
+			continue
+		}
+		r.printf("   Mining base = %d.%d, manufacturing base = %d.%d\n",
+			nampla.MIBase/10, nampla.MIBase%10, nampla.MABase/10, nampla.MABase%10)
+		r.printf("   Available population units = %d\n", nampla.PopUnits)
+		if nampla.Shipyards != 0 {
+			r.printf("   Shipyards = %d\n", nampla.Shipyards)
+		}
+		if nampla.Hidden {
+			r.printf("   Planet is hidden\n")
+		}
+		r.inventory(&nampla.ItemQuantity)
+	}
+
+	if len(sp.Ships) != 0 {
+		r.printf("\nShips:\n")
+		r.printf("   Name                          Location       Age  Status\n")
+		r.printf("   ------------------------------------------------------------------\n")
+		for _, ship := range sp.Ships {
+			loc := fmt.Sprintf("%d %d %d", ship.X, ship.Y, ship.Z)
+			if ship.PN != 0 {
+				loc += fmt.Sprintf(" #%d", ship.PN)
+			}
+			status := ship.Status.Description()
+			if ship.Status == fh.UNDER_CONSTRUCTION {
+				status += fmt.Sprintf(" (%d to complete)", ship.RemainingCost)
+			}
+			r.printf("   %-29s %-14s %3d  %s\n", ship.Display(), loc, ship.Age, status)
+			r.inventory(&ship.ItemQuantity)
+		}
+	}
+
+	var visited []*fh.StarData
+	for _, star := range t.Galaxy.Stars {
+		if star.VisitedBy[sp.ID] {
+			visited = append(visited, star)
+		}
+	}
+	sort.Slice(visited, func(i, j int) bool {
+		a, b := visited[i], visited[j]
+		if a.X != b.X {
+			return a.X < b.X
+		} else if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.Z < b.Z
+	})
+	for _, star := range visited {
+		r.printf("\n* * * * * * * * * * * * * * * * * * * * * * * * *\n\n")
+		if r.err == nil {
+			r.err = star.Scan(w, sp)
+		}
+	}
+
+	r.printf("\nEND OF TURN %d REPORT FOR SP %s\n", t.Galaxy.TurnNumber, sp.Name)
+	return r.err
+}
+
+// reportWriter remembers the first error so that the report can be
+// written without checking every print.
+type reportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (r *reportWriter) printf(format string, args ...interface{}) {
+	if r.err == nil {
+		_, r.err = fmt.Fprintf(r.w, format, args...)
+	}
+}
+
+// inventory prints the items with a non-zero quantity.
+func (r *reportWriter) inventory(items *[fh.MAX_ITEMS]int) {
+	for i, quantity := range items {
+		if item := fh.Item(i); quantity != 0 {
+			r.printf("      %-3s %-30s %7d\n", item.Abbr(), item, quantity)
+		}
+	}
+}
+
+// diplomacy prints the stance of a species toward every species it has
+// met or declared an enemy.
+func (r *reportWriter) diplomacy(sp *fh.SpeciesData, species []*fh.SpeciesData) {
+	r.printf("\nDiplomatic status:\n")
+	listed := false
+	for _, other := range species {
+		if relation := sp.RelationTo(other); other != sp && relation != fh.NO_CONTACT {
+			r.printf("   %-34s %s\n", "SP "+other.Name, relation)
+			listed = true
+		}
+	}
+	if !listed {
+		r.printf("   You have not met any other species.\n")
+	}
+}
+
+// namplaStatus returns a description of a named planet's status.
+func namplaStatus(nampla *fh.NamedPlanetData) string {
+	switch {
+	case nampla.Status&fh.HOME_PLANET != 0:
+		return "HOME PLANET:"
+	case nampla.Status&fh.DISBANDED_COLONY != 0:
+		return "DISBANDED COLONY:"
+	case nampla.Status&fh.MINING_COLONY != 0:
+		return "MINING COLONY:"
+	case nampla.Status&fh.RESORT_COLONY != 0:
+		return "RESORT COLONY:"
+	case nampla.Status&fh.COLONY != 0:
+		return "COLONY:"
+	}
+	return "NAMED PLANET:"
+}
@@ -0,0 +1,102 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newReportTurn returns a turn for two species that have met. SP Test
+// has a home planet and a corvette in orbit around it.
+func newReportTurn() (*Turn, *fh.SpeciesData) {
+	home := &fh.NamedPlanetData{Name: "Home", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.HOME_PLANET | fh.POPULATED, MIBase: 125, MABase: 90, PopUnits: 40, Shipyards: 1}
+	home.ItemQuantity[fh.CU] = 15
+	ship := &fh.ShipData{Name: "Scout", X: 1, Y: 2, Z: 3, PN: 1, Status: fh.IN_ORBIT, Class: fh.CT, Tonnage: 2, Age: 3}
+	sp := &fh.SpeciesData{ID: "01", Number: 1, Name: "Test", GovtName: "Council", GovtType: "Oligarchy", EconUnits: 250,
+		HomeNampla: home, Namplas: []*fh.NamedPlanetData{home}, Ships: []*fh.ShipData{ship}, NumShips: 1}
+	sp.TechLevel[fh.MI], sp.TechKnowledge[fh.MI], sp.TechEps[fh.MI] = 10, 12, 30
+	other := &fh.SpeciesData{ID: "02", Number: 2, Name: "Other"}
+	sp.Contact = fh.SpeciesSet{other.ID: true}
+
+	g := &fh.GalaxyData{TurnNumber: 3, Species: map[string]*fh.SpeciesData{sp.ID: sp, other.ID: other}}
+	t := &Turn{Galaxy: g, Orders: make(map[string]*orders.Orders), logs: make(map[string]*strings.Builder)}
+	return t, sp
+}
+
+func TestWriteReport(t *testing.T) {
+	turn, sp := newReportTurn()
+	var b strings.Builder
+	if err := turn.WriteReport(&b, sp, "SP Test did something.\n"); err != nil {
+		t.Fatal(err)
+	}
+	report := b.String()
+	if !strings.HasPrefix(report, "START OF TURN 3\n") || !strings.HasSuffix(report, "END OF TURN 3 REPORT FOR SP Test\n") {
+		t.Errorf("report does not start and end with the turn number:\n%s", report)
+	}
+	for _, line := range []string{
+		"Home planet: PL Home at 1 2 3 #1\n",
+		"   Mining         =  10/12  (30 experience points)\n",
+		"Economic units = 250\n",
+		"Diplomatic status:\n   SP Other                           neutral\n",
+		"Events of the last turn:\n\nSP Test did something.\n",
+		"HOME PLANET: PL Home at 1 2 3 #1\n",
+		"   Mining base = 12.5, manufacturing base = 9.0\n",
+		"      CU  Colonist Unit                       15\n",
+		"   CT Scout                      1 2 3 #1         3  In Orbit\n",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report does not contain %q:\n%s", line, report)
+		}
+	}
+}
+
+// TestReport checks that the report picks up the events logged by
+// earlier phases and by this one, and then removes the log.
+func TestReport(t *testing.T) {
+	turn, sp := newReportTurn()
+	dir := t.TempDir()
+	turn.logDir, turn.ReportDir = dir, dir
+	logName := filepath.Join(dir, "sp01.log")
+	if err := ioutil.WriteFile(logName, []byte("Logged by an earlier phase.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	turn.Logf(sp, "Logged by this phase.\n")
+	if err := turn.Report(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "sp01.rpt.t3"))
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(data), "Logged by an earlier phase.\nLogged by this phase.\n") {
+		t.Errorf("report does not contain the events in order:\n%s", data)
+	}
+	if _, err := os.Stat(logName); !os.IsNotExist(err) {
+		t.Errorf("log was not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sp02.rpt.t3")); err != nil {
+		t.Errorf("no report for SP Other: %v", err)
+	}
+}
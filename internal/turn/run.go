@@ -0,0 +1,187 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Phase is one step in running a turn.
+type Phase struct {
+	Name string
+	Run  func(t *Turn) error
+}
+
+// Phases are the steps of a turn, in the order they must be run.
+var Phases = []Phase{
+	{"locations", (*Turn).Locations},
+	{"combat", (*Turn).Combat},
+	{"pre-departure", (*Turn).PreDeparture},
+	{"jumps", (*Turn).Jump},
+	{"production", (*Turn).Production},
+	{"post-arrival", (*Turn).PostArrival},
+	{"strikes", (*Turn).Strikes},
+	{"finish", (*Turn).Finish},
+	{"report", (*Turn).Report},
+	{"stats", (*Turn).Stats},
+}
+
+// Checkpoint records the progress of a turn. It is saved after every
+// phase so that a turn that fails part way through can be resumed
+// from the last phase that succeeded.
+type Checkpoint struct {
+	TurnNumber int      `json:"turn_number"` // turn number when the turn was started
+	Completed  []string `json:"completed"`   // names of the phases that have finished
+	GalaxyFile string   `json:"galaxy_file"` // galaxy saved after the last completed phase
+}
+
+// checkpointFile is the name of the file that holds the checkpoint.
+const checkpointFile = "checkpoint.json"
+
+// ReadCheckpoint loads the checkpoint from a directory.
+// It returns nil if there is no checkpoint.
+func ReadCheckpoint(dir string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, checkpointFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("%s: %w", checkpointFile, err)
+	}
+	return &cp, nil
+}
+
+// write saves the checkpoint to a directory.
+func (cp *Checkpoint) write(dir string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, checkpointFile), data, 0644)
+}
+
+// RemoveCheckpoint deletes the checkpoint once the turn is complete.
+// The galaxy files saved after each phase are kept for the record.
+func RemoveCheckpoint(dir string) error {
+	if err := os.Remove(filepath.Join(dir, checkpointFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// StartFile returns the name of the galaxy saved in the checkpoint
+// directory before the first phase of a turn.
+func StartFile(dir string, turnNumber int) string {
+	return filepath.Join(dir, fmt.Sprintf("galaxy.t%d.00-start.json", turnNumber))
+}
+
+// PhaseFile returns the name of the galaxy saved in the checkpoint
+// directory after a phase of a turn. The phase is an index into Phases.
+func PhaseFile(dir string, turnNumber, phase int) string {
+	return filepath.Join(dir, fmt.Sprintf("galaxy.t%d.%02d-%s.json", turnNumber, phase+1, Phases[phase].Name))
+}
+
+// RunPhases runs the phases of the turn that aren't in the checkpoint.
+// A new turn saves the galaxy to the checkpoint directory before the
+// first phase, and every run copies the orders it parsed there, so that
+// the turn can be verified later. After each phase the galaxy is
+// checked, the species logs are written, the galaxy is saved to the
+// checkpoint directory and the checkpoint is updated. If a phase fails
+// or leaves the galaxy invalid, its log entries are discarded and the
+// error is returned; the turn can then be resumed by loading the
+// checkpoint galaxy and calling RunPhases with the same checkpoint.
+func (t *Turn) RunPhases(cp *Checkpoint, dir string) error {
+	if len(cp.Completed) == 0 {
+		if err := t.Galaxy.Write(StartFile(dir, cp.TurnNumber)); err != nil {
+			return err
+		}
+	}
+	if err := t.copyOrders(dir); err != nil {
+		return err
+	}
+	for i, phase := range Phases {
+		if i < len(cp.Completed) {
+			if cp.Completed[i] != phase.Name {
+				return fmt.Errorf("checkpoint: expected phase %q, found %q", phase.Name, cp.Completed[i])
+			}
+			continue
+		}
+
+		if err := phase.Run(t); err != nil {
+			t.discardLogs()
+			return fmt.Errorf("%s: %w", phase.Name, err)
+		} else if err := t.checkGalaxy(); err != nil {
+			t.discardLogs()
+			return fmt.Errorf("%s: %w", phase.Name, err)
+		}
+		if err := t.WriteLogs(); err != nil {
+			return fmt.Errorf("%s: %w", phase.Name, err)
+		}
+
+		name := PhaseFile(dir, cp.TurnNumber, i)
+		if err := t.Galaxy.Write(name); err != nil {
+			return fmt.Errorf("%s: %w", phase.Name, err)
+		}
+		cp.Completed, cp.GalaxyFile = append(cp.Completed, phase.Name), name
+		if err := cp.write(dir); err != nil {
+			return fmt.Errorf("%s: %w", phase.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkGalaxy returns an error if any species breaks the invariants
+// that the finish phase checks, so that a phase that corrupts the
+// galaxy is never saved to a checkpoint.
+func (t *Turn) checkGalaxy() error {
+	for _, sp := range t.AllSpecies() {
+		if err := checkSpecies(sp); err != nil {
+			return fmt.Errorf("SP %s: %w", sp.Name, err)
+		}
+	}
+	return nil
+}
+
+// copyOrders copies the orders files that were parsed for the turn to a
+// directory, keeping their names.
+func (t *Turn) copyOrders(dir string) error {
+	for _, name := range t.orderFiles {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(name)), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewCheckpoint returns a checkpoint for a turn that hasn't started.
+func NewCheckpoint(g *fh.GalaxyData) *Checkpoint {
+	return &Checkpoint{TurnNumber: g.TurnNumber}
+}
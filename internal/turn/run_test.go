@@ -0,0 +1,80 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"github.com/mdhender/farHorizons/internal/fh"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withPhases replaces the phases of a turn for the length of a test.
+func withPhases(t *testing.T, phases ...Phase) {
+	saved := Phases
+	Phases = phases
+	t.Cleanup(func() { Phases = saved })
+}
+
+func TestRunPhasesRejectsInvalidGalaxy(t *testing.T) {
+	withPhases(t,
+		Phase{"ok", func(t *Turn) error { return nil }},
+		Phase{"corrupt", func(t *Turn) error {
+			t.Galaxy.Species["01"].Namplas[0].ItemQuantity[fh.CU] = -1
+			return nil
+		}},
+	)
+	dir := t.TempDir()
+	turn, _ := newTestTurn()
+	turn.logDir = dir
+	cp := NewCheckpoint(turn.Galaxy)
+	err := turn.RunPhases(cp, dir)
+	if err == nil || !strings.Contains(err.Error(), "corrupt: SP Test: PL Home: negative quantity of CU (-1)") {
+		t.Fatalf("run: got %v, want the invalid galaxy to be reported", err)
+	}
+	if len(cp.Completed) != 1 || cp.GalaxyFile != PhaseFile(dir, cp.TurnNumber, 0) {
+		t.Errorf("checkpoint: got %v and %q, want only the first phase", cp.Completed, cp.GalaxyFile)
+	}
+	if _, err := os.Stat(PhaseFile(dir, cp.TurnNumber, 1)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint: the invalid galaxy was saved")
+	}
+}
+
+func TestRunPhasesCopiesOrdersOnResume(t *testing.T) {
+	withPhases(t, Phase{"ok", func(t *Turn) error { return nil }}, Phase{"resumed", func(t *Turn) error { return nil }})
+	dir, ordersDir := t.TempDir(), t.TempDir()
+	name := filepath.Join(ordersDir, "sp01.ord")
+	if err := ioutil.WriteFile(name, []byte("START PRODUCTION\nEND\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	turn, _ := newTestTurn()
+	turn.logDir, turn.orderFiles = dir, []string{name}
+	cp := &Checkpoint{Completed: []string{"ok"}}
+	if err := turn.RunPhases(cp, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sp01.ord")); err != nil {
+		t.Errorf("orders: %v", err)
+	}
+	if len(cp.Completed) != 2 {
+		t.Errorf("checkpoint: got %v, want both phases", cp.Completed)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Stats writes the game master's statistics for the turn to stats.tTT
+// in the report directory.
+func (t *Turn) Stats() error {
+	name := filepath.Join(t.ReportDir, fmt.Sprintf("stats.t%d", t.Galaxy.TurnNumber))
+	w, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := t.WriteStats(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// WriteStats writes a line of statistics for each species followed by
+// the averages for the galaxy.
+func (t *Turn) WriteStats(w io.Writer) error {
+	r := &reportWriter{w: w}
+	r.printf("Statistics for turn %d\n\n", t.Galaxy.TurnNumber)
+	r.printf("Sp Name                  MI  MA  ML  GV  LS  BI  Planets     Pop  Production  Ships  Tonnage  Banked\n")
+	r.printf("-- -------------------- --- --- --- --- --- --- ------- ------- ----------- ------ -------- -------\n")
+
+	var total struct {
+		techLevels                                 [6]int
+		planets, pop, production, ships, tons, eus int
+	}
+	species := t.AllSpecies()
+	for _, sp := range species {
+		planets, pop, production := 0, 0, 0
+		for _, nampla := range sp.Namplas {
+			if nampla.Status&fh.POPULATED == 0 || nampla.Status&fh.DISBANDED_COLONY != 0 {
+				continue
+			}
+			planets++
+			pop += nampla.PopUnits
+			production += t.planetProduction(sp, nampla)
+		}
+		tons := 0
+		for _, ship := range sp.Ships {
+			tons += ship.Tonnage
+		}
+
+		r.printf("%2d %-20.20s", sp.Number, sp.Name)
+		for tech, level := range sp.TechLevel {
+			r.printf(" %3d", level)
+			total.techLevels[tech] += level
+		}
+		r.printf(" %7d %7d %11d %6d %8d %7d\n", planets, pop, production, len(sp.Ships), 10000*tons, sp.EconUnits)
+
+		total.planets += planets
+		total.pop += pop
+		total.production += production
+		total.ships += len(sp.Ships)
+		total.tons += tons
+		total.eus += sp.EconUnits
+	}
+
+	if n := len(species); n != 0 {
+		r.printf("-- -------------------- --- --- --- --- --- --- ------- ------- ----------- ------ -------- -------\n")
+		r.printf("   %-20s", "Average")
+		for _, level := range total.techLevels {
+			r.printf(" %3d", level/n)
+		}
+		r.printf(" %7d %7d %11d %6d %8d %7d\n", total.planets/n, total.pop/n, total.production/n, total.ships/n, 10000*total.tons/n, total.eus/n)
+	}
+	return r.err
+}
@@ -0,0 +1,177 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package turn implements the phases that run a turn: combat, the
+// movement and production phases, and the end of turn processing.
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+	"github.com/mdhender/farHorizons/internal/orders"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Turn holds the data shared by the phases of a single turn.
+type Turn struct {
+	Galaxy    *fh.GalaxyData
+	Orders    map[string]*orders.Orders // orders for each species, by species id
+	ReportDir string                    // directory for the turn reports, defaults to the log directory
+	logDir    string
+	logs      map[string]*strings.Builder // log for each species, by species id
+
+	parseErrors map[string]orders.ErrorList // problems found while parsing orders, by species id
+	orderFiles  []string                    // orders files that were parsed, copied to the checkpoint directory
+	locations   *fh.LocationIndex           // built on demand, cleared when ships or planets move
+}
+
+// New loads the orders for every species and returns a Turn that is
+// ready to run phases against the galaxy. Orders files are named
+// spNN.ord, where NN is the species number. A species that doesn't
+// have an orders file gets an empty set of orders. Problems found
+// while parsing orders are kept until the first phase of the turn
+// writes them to the species log.
+func New(g *fh.GalaxyData, ordersDir, logDir string) (*Turn, error) {
+	t := &Turn{
+		Galaxy:    g,
+		Orders:    make(map[string]*orders.Orders),
+		ReportDir: logDir,
+		logDir:    logDir,
+		logs:      make(map[string]*strings.Builder),
+
+		parseErrors: make(map[string]orders.ErrorList),
+	}
+	for _, sp := range t.AllSpecies() {
+		name := filepath.Join(ordersDir, fmt.Sprintf("sp%s.ord", sp.ID))
+		o, err := orders.ParseFile(name)
+		if os.IsNotExist(err) {
+			t.Orders[sp.ID] = &orders.Orders{}
+			continue
+		} else if list, ok := err.(orders.ErrorList); ok {
+			t.parseErrors[sp.ID] = list
+		} else if err != nil {
+			return nil, err
+		}
+		t.Orders[sp.ID] = o
+		t.orderFiles = append(t.orderFiles, name)
+	}
+	return t, nil
+}
+
+// logParseErrors writes the problems found while parsing orders to the
+// species logs. It is called by the first phase of the turn so that the
+// problems are only reported once.
+func (t *Turn) logParseErrors() {
+	for _, sp := range t.AllSpecies() {
+		for _, e := range t.parseErrors[sp.ID] {
+			t.Logf(sp, "!!! Order ignored on line %d: %s\n", e.Line, e.Msg)
+		}
+	}
+}
+
+// LocationIndex returns the index of the species present in each star
+// system, building it if ships or planets have moved since it was built.
+func (t *Turn) LocationIndex() *fh.LocationIndex {
+	if t.locations == nil {
+		t.locations = fh.NewLocationIndex(t.Galaxy)
+	}
+	return t.locations
+}
+
+// AllSpecies returns the species in the galaxy, sorted by species number.
+func (t *Turn) AllSpecies() []*fh.SpeciesData {
+	return speciesOf(t.Galaxy)
+}
+
+// speciesOf returns the species in a galaxy, sorted by species number.
+func speciesOf(g *fh.GalaxyData) []*fh.SpeciesData {
+	var species []*fh.SpeciesData
+	for _, sp := range g.Species {
+		species = append(species, sp)
+	}
+	sort.Slice(species, func(i, j int) bool {
+		return species[i].Number < species[j].Number
+	})
+	return species
+}
+
+// getSpecies returns the species named by an SP argument or a species number.
+func (t *Turn) getSpecies(arg *orders.Arg) *fh.SpeciesData {
+	if arg.Kind == orders.NUMBER {
+		return t.Galaxy.GetSpeciesByID(fmt.Sprintf("%02d", arg.Number))
+	}
+	if sp := t.Galaxy.GetSpeciesByName(arg.Name); sp != nil {
+		return sp
+	}
+	for _, sp := range t.Galaxy.Species {
+		if strings.EqualFold(sp.Name, arg.Name) {
+			return sp
+		}
+	}
+	return nil
+}
+
+// Commands returns the commands a species gave in a section.
+func (t *Turn) Commands(sp *fh.SpeciesData, section orders.Section) []*orders.Command {
+	return t.Orders[sp.ID].Section(section)
+}
+
+// Logf adds a line to the log of a species.
+// The log becomes part of the species' report for the turn.
+func (t *Turn) Logf(sp *fh.SpeciesData, format string, args ...interface{}) {
+	log, ok := t.logs[sp.ID]
+	if !ok {
+		log = &strings.Builder{}
+		t.logs[sp.ID] = log
+	}
+	fmt.Fprintf(log, format, args...)
+}
+
+// discardLogs drops the log entries that haven't been written yet.
+func (t *Turn) discardLogs() {
+	for _, log := range t.logs {
+		log.Reset()
+	}
+}
+
+// WriteLogs appends the species logs to their spNN.log files.
+func (t *Turn) WriteLogs() error {
+	for _, sp := range t.AllSpecies() {
+		log, ok := t.logs[sp.ID]
+		if !ok || log.Len() == 0 {
+			continue
+		}
+		name := filepath.Join(t.logDir, fmt.Sprintf("sp%s.log", sp.ID))
+		fp, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := fp.WriteString(log.String()); err != nil {
+			fp.Close()
+			return err
+		}
+		if err := fp.Close(); err != nil {
+			return err
+		}
+		log.Reset()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package turn
+
+import (
+	"fmt"
+	"github.com/mdhender/farHorizons/internal/fh"
+)
+
+// Verification is the result of running a turn again.
+type Verification struct {
+	TurnNumber int
+	Expected   string // hash of the galaxy saved after the last phase
+	Actual     string // hash of the galaxy after running the turn again
+	Difference string // first species, planet or ship that differs, if any
+}
+
+// OK returns true if running the turn again gave the same galaxy.
+func (v *Verification) OK() bool {
+	return v.Expected == v.Actual
+}
+
+// Verify runs a turn again, starting from the galaxy saved in the
+// checkpoint directory before the turn's first phase and using the
+// orders copied there, and compares the result with the galaxy saved
+// after its last phase. The random number state is part of the saved galaxy, so an
+// honest run gives the same hash. Logs, reports and checkpoints from
+// the new run are written to workDir.
+func Verify(checkpointDir, workDir string, turnNumber int) (*Verification, error) {
+	start, err := fh.GetGalaxy(StartFile(checkpointDir, turnNumber))
+	if err != nil {
+		return nil, err
+	}
+	expected, err := fh.GetGalaxy(PhaseFile(checkpointDir, turnNumber, len(Phases)-1))
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := New(start, checkpointDir, workDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.RunPhases(NewCheckpoint(start), workDir); err != nil {
+		return nil, fmt.Errorf("turn %d: %w", turnNumber, err)
+	}
+	if err := RemoveCheckpoint(workDir); err != nil {
+		return nil, err
+	}
+
+	v := &Verification{TurnNumber: turnNumber}
+	if v.Expected, err = expected.Hash(); err != nil {
+		return nil, err
+	} else if v.Actual, err = t.Galaxy.Hash(); err != nil {
+		return nil, err
+	} else if !v.OK() {
+		if v.Difference, err = fh.FirstDifference(expected, t.Galaxy); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
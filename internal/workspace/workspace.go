@@ -0,0 +1,102 @@
+/*
+ * farHorizons - a clone of Far Horizons
+ * Copyright (C) 2021  Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package workspace implements the directory layout of a game.
+//
+// A workspace holds everything for one game, so several games can be
+// hosted on one machine by giving each its own directory:
+//
+//	galaxy.json   the galaxy file
+//	setup.json    the setup file used to create the galaxy
+//	orders/       the spNN.ord files for the current turn
+//	reports/      the spNN.log files during a turn, then the reports
+//	turns/NNN/    the galaxy saved before and after each phase of turn NNN
+//	messages/     the text of messages attached to stars and planets
+//	legacy/       the C server's data files, for import and export
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Workspace is the directory holding one game.
+type Workspace struct {
+	Root string
+}
+
+// New returns the workspace rooted at a directory. Nothing is created.
+func New(root string) *Workspace {
+	return &Workspace{Root: root}
+}
+
+// Create makes the workspace directory and its sub-directories.
+func (w *Workspace) Create() error {
+	for _, dir := range []string{w.Root, w.OrdersDir(), w.ReportsDir(), w.TurnsDir(), w.MessagesDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Path returns the name of a file in the top of the workspace.
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.Root, name)
+}
+
+// GalaxyFile returns the name of the galaxy file.
+func (w *Workspace) GalaxyFile() string {
+	return w.Path("galaxy.json")
+}
+
+// SetupFile returns the name of the setup file.
+func (w *Workspace) SetupFile() string {
+	return w.Path("setup.json")
+}
+
+// OrdersDir returns the directory for the orders.
+func (w *Workspace) OrdersDir() string {
+	return w.Path("orders")
+}
+
+// ReportsDir returns the directory for the species logs and reports.
+func (w *Workspace) ReportsDir() string {
+	return w.Path("reports")
+}
+
+// TurnsDir returns the directory that holds a directory for each turn.
+func (w *Workspace) TurnsDir() string {
+	return w.Path("turns")
+}
+
+// TurnDir returns the directory for the checkpoints of a turn.
+func (w *Workspace) TurnDir(turnNumber int) string {
+	return filepath.Join(w.TurnsDir(), fmt.Sprintf("%03d", turnNumber))
+}
+
+// MessagesDir returns the directory for the message text files.
+func (w *Workspace) MessagesDir() string {
+	return w.Path("messages")
+}
+
+// LegacyDir returns the directory for the C server's data files.
+func (w *Workspace) LegacyDir() string {
+	return w.Path("legacy")
+}